@@ -0,0 +1,172 @@
+package onelogin
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A CustomAttributeSchema holds the custom attributes defined for the
+// account, by shortname.
+type CustomAttributeSchema struct {
+	names map[string]bool
+}
+
+// NewCustomAttributeSchema returns a schema of the given custom attributes.
+func NewCustomAttributeSchema(names ...string) *CustomAttributeSchema {
+	schema := &CustomAttributeSchema{names: make(map[string]bool, len(names))}
+	for _, name := range names {
+		schema.names[name] = true
+	}
+
+	return schema
+}
+
+// Has reports whether the custom attribute is defined.
+func (schema *CustomAttributeSchema) Has(name string) bool {
+	return schema.names[name]
+}
+
+// Names returns the shortnames of the custom attributes, sorted.
+func (schema *CustomAttributeSchema) Names() []string {
+	names := make([]string, 0, len(schema.names))
+	for name := range schema.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Validate returns an error if any of the attributes isn't defined.
+func (schema *CustomAttributeSchema) Validate(attributes map[string]string) error {
+	var unknown []string
+	for name := range attributes {
+		if !schema.Has(name) {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown custom attributes: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// GetCustomAttributeSchema returns the schema of the custom attributes
+// defined for the account.
+func (s *UserService) GetCustomAttributeSchema(ctx context.Context) (*CustomAttributeSchema, error) {
+	names, err := s.GetCustomAttributes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCustomAttributeSchema(names...), nil
+}
+
+// LoadCustomAttributeSchema gets the schema of the custom attributes and
+// keeps it to validate the attributes passed to UpdateCustomAttributes. It
+// can be called again to reload the schema after attributes are defined.
+func (s *UserService) LoadCustomAttributeSchema(ctx context.Context) (*CustomAttributeSchema, error) {
+	schema, err := s.GetCustomAttributeSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.schema = schema
+	s.mu.Unlock()
+
+	return schema, nil
+}
+
+// BindCustomAttributes stores the custom attributes of the user in the struct
+// pointed to by v. Fields are bound to the attribute named by their onelogin
+// tag:
+//
+//	var attrs struct {
+//		EmployeeID int    `onelogin:"employee_id"`
+//		CostCenter string `onelogin:"cost_center"`
+//		Contractor bool   `onelogin:"contractor"`
+//	}
+//	err := user.BindCustomAttributes(&attrs)
+//
+// Fields may be strings, booleans, numbers or implement
+// encoding.TextUnmarshaler. Fields of missing or empty attributes are left
+// untouched.
+func (u *User) BindCustomAttributes(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("custom attributes: v must be a pointer to a struct")
+	}
+	rv = rv.Elem()
+
+	for i := 0; i < rv.NumField(); i++ {
+		name := rv.Type().Field(i).Tag.Get("onelogin")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		value, ok := u.CustomAttributes[name]
+		if !ok || value == "" {
+			continue
+		}
+
+		if err := setField(rv.Field(i), value); err != nil {
+			return fmt.Errorf("custom attribute %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// setField parses s into the field f.
+func setField(f reflect.Value, s string) error {
+	if !f.CanSet() {
+		return errors.New("field is not exported")
+	}
+
+	if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %v", f.Type())
+	}
+
+	return nil
+}
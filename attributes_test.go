@@ -0,0 +1,76 @@
+package onelogin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestUserService_LoadCustomAttributeSchema(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/custom_attributes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[["employee_id","cost_center"]]}`)
+	})
+	mux.HandleFunc("/api/1/users/1/set_custom_attributes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+	})
+
+	schema, err := c.User.LoadCustomAttributeSchema(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := schema.Names(); len(got) != 2 || got[0] != "cost_center" || got[1] != "employee_id" {
+		t.Errorf("names got: %v", got)
+	}
+
+	err = c.User.UpdateCustomAttributes(context.Background(), 1, map[string]string{"employee_id": "42", "costcenter": "R&D"})
+	if err == nil || err.Error() != "unknown custom attributes: costcenter" {
+		t.Errorf("got: %v", err)
+	}
+
+	if err := c.User.UpdateCustomAttributes(context.Background(), 1, map[string]string{"employee_id": "42"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUser_BindCustomAttributes(t *testing.T) {
+	u := &User{CustomAttributes: map[string]string{
+		"employee_id": "42",
+		"contractor":  "true",
+		"cost_center": "R&D",
+		"start_date":  "2019-03-12T00:00:00Z",
+		"rate":        "",
+	}}
+
+	var attrs struct {
+		EmployeeID int       `onelogin:"employee_id"`
+		Contractor bool      `onelogin:"contractor"`
+		CostCenter string    `onelogin:"cost_center"`
+		StartDate  time.Time `onelogin:"start_date"`
+		Rate       float64   `onelogin:"rate"`
+		Other      string
+	}
+	attrs.Rate = 1.5
+
+	if err := u.BindCustomAttributes(&attrs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if attrs.EmployeeID != 42 || !attrs.Contractor || attrs.CostCenter != "R&D" || attrs.Rate != 1.5 {
+		t.Errorf("got: %+v", attrs)
+	}
+	if want := time.Date(2019, 3, 12, 0, 0, 0, 0, time.UTC); !attrs.StartDate.Equal(want) {
+		t.Errorf("start date got: %v, want: %v", attrs.StartDate, want)
+	}
+
+	var invalid struct {
+		EmployeeID bool `onelogin:"employee_id"`
+	}
+	if err := u.BindCustomAttributes(&invalid); err == nil {
+		t.Error("expected an error for an invalid value")
+	}
+}
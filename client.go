@@ -10,7 +10,9 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/go-querystring/query"
 )
@@ -23,6 +25,21 @@ type service struct {
 	client *Client
 }
 
+// send sends an authorized request, its response is decoded into v.
+func (s *service) send(ctx context.Context, method, u string, body, v interface{}) error {
+	req, err := s.client.NewRequest(method, u, body)
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.AddAuthorization(ctx, req); err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(ctx, req, v)
+	return err
+}
+
 // A Client interacts with OneLogin.
 type Client struct {
 	client  *http.Client
@@ -33,48 +50,97 @@ type Client struct {
 	subdomain    string
 	// User agent used when communicating with the OneLogin api.
 	UserAgent string
+	// RetryPolicy used by Do to retry transient failures, nil disables retries.
+	RetryPolicy *RetryPolicy
+	// Limiter paces outgoing requests, nil disables client-side rate limiting.
+	Limiter Limiter
 	// Reuse a single struct instead of allocating one for each service on the heap.
-	common     service
-	oauthToken *oauthToken
+	common service
+	// Deprecated: the client doesn't use its Mutex anymore, it is kept for
+	// backwards compatibility.
 	sync.Mutex
 
+	// The token of the client, and the token acquisition in flight if any.
+	tokenMu     sync.RWMutex
+	oauthToken  *oauthToken
+	flight      *tokenFlight
+	nextRefresh time.Time
+	rejected    string // access token rejected by OneLogin, see invalidateToken
+	closed      bool   // no background refresh after Close
+
+	// Optional source of tokens, replaces the tokens issued by the client.
+	tokenSource TokenSource
+	// Optional store where the tokens issued by the client are persisted.
+	tokenStore TokenStore
+
+	// Last rate limit reported by OneLogin.
+	rateMu sync.Mutex
+	rate   Rate
+
 	// Namespaced services
 	// https://developers.onelogin.com/api-docs/1/getting-started/dev-overview
-	Oauth       *OauthService
-	Login       *LoginService
-	User        *UserService
-	Role        *RoleService
-	Group       *GroupService
+	Oauth  *OauthService
+	Login  *LoginService
+	User   *UserService
+	Role   *RoleService
+	Group  *GroupService
+	SAML   *SAMLService
+	MFA    *MFAService
+	Invite *InviteService
+	Event  *EventService
+
+	// Deprecated: use SAML instead, SAMLService is kept for backwards
+	// compatibility.
 	SAMLService *SAMLService
-	// EventService *EventService
 }
 
-// New returns a new OneLogin client.
+// New returns a new OneLogin client. See NewWithOptions to further configure
+// the client and validate its configuration.
 func New(clientID, clientSecret, shard, subdomain string) *Client {
-	c := &Client{
-		client:       http.DefaultClient,
+	u, _ := url.Parse(buildURL(baseURL, shard))
+
+	return newClient(&options{
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		subdomain:    subdomain,
+		baseURL:      u,
+		httpClient:   http.DefaultClient,
+		retryPolicy:  DefaultRetryPolicy(),
+	})
+}
+
+// newClient returns a client configured by o.
+func newClient(o *options) *Client {
+	c := &Client{
+		client:       o.httpClient,
+		BaseURL:      o.baseURL,
+		clientID:     o.clientID,
+		clientSecret: o.clientSecret,
+		subdomain:    o.subdomain,
+		UserAgent:    o.userAgent,
+		RetryPolicy:  o.retryPolicy,
+		Limiter:      o.limiter,
+		tokenSource:  o.tokenSource,
+		tokenStore:   o.tokenStore,
 	}
 	c.common.client = c
-	c.BaseURL, _ = url.Parse(buildURL(baseURL, shard))
 	c.Oauth = &OauthService{service: &c.common}
 	c.Login = &LoginService{service: &c.common}
 	c.User = &UserService{service: &c.common}
-	c.Role = &RoleService{service: &c.common}
+	c.Role = &RoleService{service: &c.common, cacheTTL: o.roleCacheTTL}
 	c.Group = &GroupService{service: &c.common}
-	c.SAMLService = &SAMLService{service: &c.common}
+	c.SAML = &SAMLService{service: &c.common}
+	c.SAMLService = c.SAML
+	c.MFA = &MFAService{service: &c.common}
+	c.Invite = &InviteService{service: &c.common}
+	c.Event = &EventService{service: &c.common}
 
 	return c
 }
 
-type urlQuery struct {
-	AfterCursor string `url:"after_cursor,omitempty"`
-}
-
 // addOptions adds the parameters in opt as URL query parameters to s. opt
-// must be a struct whose fields may contain "url" tags.
+// must be a struct whose fields may contain "url" tags. Parameters already in
+// s are kept, unless opt overrides them.
 func addOptions(s string, opt interface{}) (string, error) {
 	v := reflect.ValueOf(opt)
 	if v.Kind() == reflect.Ptr && v.IsNil() {
@@ -91,58 +157,188 @@ func addOptions(s string, opt interface{}) (string, error) {
 		return s, err
 	}
 
-	u.RawQuery = qs.Encode()
+	q := u.Query()
+	for k, v := range qs {
+		q[k] = v
+	}
+
+	u.RawQuery = q.Encode()
 	return u.String(), nil
 }
 
 // AddAuthorization injects the Authorization header to the request.
-// If the client doesn't has an oauthToken, a new token is issed.
-// If the token is nearly expired, it is automatically refreshed.
+// If the client has a TokenSource, the token is taken from it. Otherwise,
+// if the client doesn't has an oauthToken, it is loaded from the TokenStore
+// or a new token is issed. If the token is nearly expired, it is
+// automatically refreshed.
+//
+// Concurrent callers share a single token acquisition, each of them waits
+// for it until its own ctx is done. Tokens are refreshed in the background
+// shortly before they are nearly expired, so that requests are seldom held
+// up by a refresh.
 func (c *Client) AddAuthorization(ctx context.Context, req *http.Request) error {
-	c.Lock()
-	defer c.Unlock()
-
-	if c.oauthToken == nil {
-		var err error
-
-		c.oauthToken, err = c.Oauth.getToken(ctx)
+	if c.tokenSource != nil {
+		t, err := c.tokenSource.Token(ctx)
 		if err != nil {
 			return err
 		}
+
+		setBearer(req, t.AccessToken)
+		return nil
 	}
 
-	if c.oauthToken.isNearExpired() {
-		if err := c.oauthToken.refresh(ctx); err != nil {
-			return err
-		}
+	t, err := c.token(ctx)
+	if err != nil {
+		return err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("bearer:%s", c.oauthToken.AccessToken))
+	setBearer(req, t.AccessToken)
 
 	return nil
 }
 
+// setBearer sets the access token as the Authorization header of req. The
+// v1 API takes its own "bearer:<token>" form, the v2 API the standard
+// "Bearer <token>".
+func setBearer(req *http.Request, accessToken string) {
+	if isV2(req.URL.Path) {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		return
+	}
+
+	req.Header.Set("Authorization", "bearer:"+accessToken)
+}
+
+// bearer returns the access token of the Authorization header of req, in
+// either of the forms set by setBearer.
+func bearer(req *http.Request) (string, bool) {
+	auth := req.Header.Get("Authorization")
+	for _, prefix := range []string{"bearer:", "Bearer "} {
+		if strings.HasPrefix(auth, prefix) {
+			return strings.TrimPrefix(auth, prefix), true
+		}
+	}
+
+	return "", false
+}
+
+// retryUnauthorized retries req once with a new token if resp reports that
+// the token of the client has been rejected (e.g., it has been revoked). The
+// body of resp is consumed if req is retried.
+func (c *Client) retryUnauthorized(ctx context.Context, req *http.Request, resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode != http.StatusUnauthorized || (req.Body != nil && req.GetBody == nil) {
+		return resp, nil
+	}
+
+	// invalid credentials or MFA tokens are also reported as unauthorized,
+	// retrying those would count as another failed login attempt
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	_, _, message := parseErrorMessage(data)
+	if classifyError(resp.StatusCode, message) != nil {
+		return resp, nil
+	}
+
+	if !c.invalidateToken(ctx, req) {
+		return resp, nil
+	}
+
+	if err := c.AddAuthorization(ctx, req); err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		if req.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.send(ctx, req)
+}
+
+// Close revokes the access token issued to the client, and removes it from
+// the TokenStore. Note that other clients sharing the token through the
+// TokenStore will have to issue a new one. Tokens supplied by a TokenSource
+// are not revoked. A token acquisition in flight is waited for, and the token
+// it acquired is revoked too. Every token is revoked even if revoking another
+// one failed, the errors are combined. The client remains usable, a new token
+// is issued for the next request.
+func (c *Client) Close(ctx context.Context) error {
+	c.tokenMu.Lock()
+	c.closed = true
+	old := c.oauthToken
+	f := c.flight
+	c.tokenMu.Unlock()
+
+	if f != nil {
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	c.tokenMu.Lock()
+	t := c.oauthToken
+	c.oauthToken = nil
+	c.tokenMu.Unlock()
+
+	tokens := []*oauthToken{t}
+	if old != t {
+		// replaced by the acquisition in flight
+		tokens = append(tokens, old)
+	}
+
+	var (
+		revoked bool
+		errs    multiError
+	)
+	for _, token := range tokens {
+		if token == nil {
+			continue
+		}
+		if err := c.Oauth.RevokeToken(ctx, token.AccessToken); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		revoked = true
+	}
+
+	if revoked && c.tokenStore != nil {
+		if err := c.tokenStore.Delete(ctx, c.clientID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs.err()
+}
+
 // Do sends an API request and returns the API response. The API response is
 // JSON decoded and stored in the value pointed to by v, or returned as an
 // error if an API error has occurred. If v implements the io.Writer
 // interface, the raw response body will be written to v, without attempting to
 // first decode it.
 //
+// Transient failures are retried according to the client RetryPolicy. If
+// the access token of the client is rejected, the request is retried once
+// with a new token.
+//
 // The provided ctx must be non-nil. If it is canceled or times out,
 // ctx.Err() will be returned.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
 	req = req.WithContext(ctx)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.send(ctx, req)
 	if err != nil {
-		// If we got an error, and the context has been canceled,
-		// the context's error is probably more useful.
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
+		return nil, err
+	}
 
+	resp, err = c.retryUnauthorized(ctx, req, resp)
+	if err != nil {
 		return nil, err
 	}
 
@@ -163,6 +359,15 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	if v != nil {
 		if w, ok := v.(io.Writer); ok {
 			io.Copy(w, resp.Body)
+		} else if isV2(req.URL.Path) {
+			// v2 responses aren't wrapped, and are paginated by headers
+			err = json.NewDecoder(resp.Body).Decode(v)
+			if err == io.EOF {
+				err = nil
+			}
+
+			response.PaginationAfterCursor = headerCursor(resp.Header, "After-Cursor")
+			response.PaginationBeforeCursor = headerCursor(resp.Header, "Before-Cursor")
 		} else {
 			var m responseMessage
 			err = json.NewDecoder(resp.Body).Decode(&m)
@@ -185,8 +390,24 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	return response, err
 }
 
+// isV2 reports whether the path is an endpoint of the v2 API, whose responses
+// and errors differ from the v1 API.
+func isV2(path string) bool {
+	return strings.Contains(path, "/api/2/")
+}
+
+// headerCursor returns the pagination cursor of a v2 response held by the
+// header key, or nil.
+func headerCursor(h http.Header, key string) *string {
+	if c := h.Get(key); c != "" {
+		return &c
+	}
+
+	return nil
+}
+
 func newResponse(resp *http.Response) *Response {
-	return &Response{Response: resp}
+	return &Response{Response: resp, Rate: parseRate(resp.Header)}
 }
 
 // NewRequest instantiate a new http.Request from a method, url and body.
@@ -239,9 +460,32 @@ type responseMessage struct {
 	Data json.RawMessage `json:"data"`
 }
 
+// v2ErrorMessage is the body of the errors returned by the v2 API.
+type v2ErrorMessage struct {
+	StatusCode int64  `json:"statusCode"`
+	Name       string `json:"name"`
+	Message    string `json:"message"`
+}
+
+// parseErrorMessage returns the code, type and message of the error reported
+// by the body of a response, of either the v1 or the v2 API.
+func parseErrorMessage(data []byte) (code int64, typ, message string) {
+	var m responseMessage
+	_ = json.Unmarshal(data, &m)
+	if m.Status.Code != 0 || m.Status.Message != "" {
+		return m.Status.Code, m.Status.Type, m.Status.Message
+	}
+
+	var e v2ErrorMessage
+	_ = json.Unmarshal(data, &e)
+	return e.StatusCode, e.Name, e.Message
+}
+
 // CheckResponse checks the *http.Response.
 // HTTP status codes ranging from 200 to 299 are considered are successes.
 // Otherwise an error happen, and the error gets unmarshalled and returned into the error.
+// The error wraps one of the Err* errors when its cause is known, and is a
+// *RateLimitError when the rate limit has been exceeded.
 func CheckResponse(r *http.Response) error {
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return nil
@@ -250,23 +494,26 @@ func CheckResponse(r *http.Response) error {
 	errorResponse := &ErrorResponse{Response: r}
 	data, err := ioutil.ReadAll(r.Body)
 	if err == nil && data != nil {
-		var m responseMessage
-		_ = json.Unmarshal(data, &m)
-		errorResponse.Code = m.Status.Code
-		errorResponse.Type = m.Status.Type
-		errorResponse.Message = m.Status.Message
+		errorResponse.Code, errorResponse.Type, errorResponse.Message = parseErrorMessage(data)
+	}
+	errorResponse.err = classifyError(r.StatusCode, errorResponse.Message)
+
+	if r.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{ErrorResponse: errorResponse, Rate: parseRate(r.Header)}
 	}
 
-	// TODO: handle the different errors here, such as MFA, Rate limit, etc...
 	return errorResponse
 }
 
-// Response embeds a *http.Response as well as some Paginations values.
+// Response embeds a *http.Response as well as some Paginations values and
+// the rate limit reported by OneLogin.
 type Response struct {
 	*http.Response
 
 	PaginationAfterCursor  *string
 	PaginationBeforeCursor *string
+
+	Rate Rate
 }
 
 // An ErrorResponse reports an error caused by an API request.
@@ -287,6 +534,8 @@ type ErrorResponse struct {
 	Code    int64
 	Type    string
 	Message string
+
+	err error // classification of the error, see Unwrap
 }
 
 func (r *ErrorResponse) Error() string {
@@ -295,6 +544,18 @@ func (r *ErrorResponse) Error() string {
 		r.Response.StatusCode, r.Type, r.Message)
 }
 
+// Unwrap returns the classification of the error (e.g., ErrNotFound), or nil
+// if it is unknown.
+func (r *ErrorResponse) Unwrap() error {
+	return r.err
+}
+
 func buildURL(baseURL string, args ...interface{}) string {
 	return fmt.Sprintf(baseURL, args...)
 }
+
+// String returns a pointer to v, to set optional string fields.
+func String(v string) *string { return &v }
+
+// Int64 returns a pointer to v, to set optional int64 fields.
+func Int64(v int64) *int64 { return &v }
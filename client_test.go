@@ -0,0 +1,151 @@
+package onelogin
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// setup starts a test server that mimics OneLogin and returns a client that
+// talks to it. The token endpoint is already handled, tests register their
+// own handlers on the returned mux.
+func setup() (c *Client, mux *http.ServeMux, teardown func()) {
+	mux = http.NewServeMux()
+	mux.HandleFunc("/auth/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"status":{"error":false,"code":200,"type":"success","message":"Success"},
+			"data":[{"access_token":"token","created_at":%q,"expires_in":36000,"refresh_token":"refresh","token_type":"bearer","account_id":1}]}`,
+			time.Now().UTC().Format(time.RFC3339Nano))
+	})
+	server := httptest.NewServer(mux)
+
+	c, err := NewWithOptions(
+		WithCredentials("clientID", "clientSecret"),
+		WithSubdomain("myteam"),
+		WithBaseURL(server.URL),
+		WithRetryPolicy(&RetryPolicy{
+			MaxAttempts:          3,
+			BaseDelay:            time.Millisecond,
+			MaxDelay:             10 * time.Millisecond,
+			RetryableStatusCodes: DefaultRetryPolicy().RetryableStatusCodes,
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return c, mux, server.Close
+}
+
+func TestClientDo_retry(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		status     int
+		header     http.Header
+		wantCalls  int
+		wantStatus int
+	}{
+		{"idempotent request on server error", "GET", 503, nil, 3, 200},
+		{"non-idempotent request on server error", "POST", 500, nil, 1, 500},
+		{"non-idempotent request on rate limit", "POST", 429, nil, 3, 200},
+		{"client error", "GET", 400, nil, 1, 400},
+		{"retry-after within max delay", "GET", 429, http.Header{"Retry-After": {"0"}}, 3, 200},
+		{"retry-after beyond max delay", "GET", 429, http.Header{"Retry-After": {"60"}}, 1, 429},
+		{"rate limit reset beyond max delay", "GET", 429, http.Header{"X-Ratelimit-Reset": {"60"}}, 1, 429},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mux, teardown := setup()
+			defer teardown()
+
+			var calls int
+			mux.HandleFunc("/api/1/test", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if b, _ := ioutil.ReadAll(r.Body); r.Method == "POST" && string(b) != "{\"a\":1}\n" {
+					t.Errorf("attempt %d: unexpected body %q", calls, b)
+				}
+				if calls < 3 {
+					for k, v := range tt.header {
+						w.Header()[k] = v
+					}
+					w.WriteHeader(tt.status)
+					return
+				}
+				fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+			})
+
+			var body interface{}
+			if tt.method == "POST" {
+				body = map[string]int{"a": 1}
+			}
+			req, err := c.NewRequest(tt.method, "/api/1/test", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, _ := c.Do(context.Background(), req, nil)
+			if calls != tt.wantCalls {
+				t.Errorf("calls got: %v, want: %v", calls, tt.wantCalls)
+			}
+			if resp == nil || resp.StatusCode != tt.wantStatus {
+				t.Errorf("status got: %v, want: %v", resp, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxDelay time.Duration
+		attempt  int
+		want     time.Duration
+	}{
+		{"first retry", 30 * time.Second, 1, time.Second},
+		{"doubled", 30 * time.Second, 3, 4 * time.Second},
+		{"capped", 30 * time.Second, 10, 30 * time.Second},
+		{"no cap", 0, 10, 512 * time.Second},
+		{"no cap without overflow", 0, 100, time.Second << 33},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &RetryPolicy{BaseDelay: time.Second, MaxDelay: tt.maxDelay}
+			if got := p.backoff(tt.attempt); got != tt.want {
+				t.Errorf("got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientDo_retryCanceled(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+	c.RetryPolicy.BaseDelay = time.Minute
+	c.RetryPolicy.MaxDelay = time.Minute
+
+	mux.HandleFunc("/api/1/test", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, _ := c.NewRequest("GET", "/api/1/test", nil)
+	_, err := c.Do(ctx, req, nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestNew_deprecatedSAMLService(t *testing.T) {
+	c := New("clientID", "clientSecret", "us", "test")
+	if c.SAML == nil || c.SAMLService != c.SAML {
+		t.Errorf("SAMLService got: %p, want: %p", c.SAMLService, c.SAML)
+	}
+}
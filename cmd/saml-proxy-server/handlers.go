@@ -54,7 +54,7 @@ func (s *server) samlPost(req *http.Request) ([]byte, error) {
 	}
 
 	saml, err :=
-		s.onelogin.SAMLService.GenerateSAMLAssertionWithVerify(context.Background(),
+		s.onelogin.SAML.GenerateSAMLAssertionWithVerify(context.Background(),
 			*t.Username, *t.Password, *t.AppID, "", cfg.mfaDevice, *t.MFAToken)
 	if err != nil {
 		return data, err
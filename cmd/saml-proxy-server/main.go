@@ -9,9 +9,14 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/asobrien/onelogin"
 )
@@ -30,7 +35,12 @@ func newOneloginClient() (*onelogin.Client, error) {
 		return nil, errors.New("config error: team is unset")
 	}
 
-	return onelogin.New(cfg.clientID, cfg.clientSecret, cfg.shard, cfg.team), nil
+	return onelogin.NewWithOptions(
+		onelogin.WithCredentials(cfg.clientID, cfg.clientSecret),
+		onelogin.WithRegion(cfg.shard),
+		onelogin.WithSubdomain(cfg.team),
+		onelogin.WithTimeout(30*time.Second),
+	)
 }
 
 func main() {
@@ -45,8 +55,34 @@ func main() {
 	}
 	srv.routes()
 
+	httpServer := &http.Server{
+		Addr:    cfg.addr,
+		Handler: srv.router,
+	}
+
+	// on SIGINT or SIGTERM, drain in-flight requests and revoke the OneLogin
+	// access token before exiting
+	done := make(chan struct{})
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Printf("server shutdown: %v", err)
+		}
+		if err := srv.onelogin.Close(ctx); err != nil {
+			log.Printf("onelogin token revocation: %v", err)
+		}
+		close(done)
+	}()
+
 	log.Printf("server listening on %s", cfg.addr)
-	if err := http.ListenAndServeTLS(cfg.addr, cfg.certFile, cfg.keyFile, srv.router); err != nil {
+	if err := httpServer.ListenAndServeTLS(cfg.certFile, cfg.keyFile); err != http.ErrServerClosed {
 		log.Fatal(err)
 	}
+	<-done
 }
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 )
 
@@ -49,32 +49,54 @@ func getDeviceID(name string, devices []*Device) (string, error) {
 // 'Google Authenticator' device can not generate a push event.
 // https://developers.onelogin.com/api-docs/1/login-page/verify-factor
 func (s *Client) verifyFactor(ctx context.Context, endpoint string, p *verifyFactorParams) (*responseMessage, error) {
+	_, m, err := s.postVerifyFactor(ctx, endpoint, p)
+	return m, err
+}
+
+// verifyFactorPush generates a push to a device (e.g., SMS) via the
+// `verify_factor` endpoint, OneLogin then reports the verification as pending.
+func (s *Client) verifyFactorPush(ctx context.Context, endpoint string, p *verifyFactorParams) error {
+	resp, m, err := s.postVerifyFactor(ctx, endpoint, p)
+	if err != nil {
+		return err
+	}
+
+	if m.Status.Type != "pending" {
+		return statusError(resp, m, ErrMFATokenInvalid)
+	}
+
+	return nil
+}
+
+// postVerifyFactor posts p to the `verify_factor` endpoint, and returns the
+// response and its message.
+func (s *Client) postVerifyFactor(ctx context.Context, endpoint string, p *verifyFactorParams) (*http.Response, *responseMessage, error) {
 	req, err := s.NewRequest("POST", endpoint, p)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := s.AddAuthorization(ctx, req); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var b bytes.Buffer
-	_, err = s.Do(ctx, req, &b)
+	resp, err := s.Do(ctx, req, &b)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var m responseMessage
 	err = json.Unmarshal(b.Bytes(), &m)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Read the associate response data upon successful verification. This is either a
 	// push event follow-up call or a verification of device with a known passcode.
 	if m.Status.Error {
-		err = errors.New("verify factor failed")
+		err = statusError(resp.Response, &m, ErrMFATokenInvalid)
 	}
 
-	return &m, err
+	return resp.Response, &m, err
 }
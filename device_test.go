@@ -0,0 +1,42 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClient_verifyFactorPush(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"pending", `{"status":{"error":false,"code":200,"type":"pending","message":"Authentication pending on OL Protect"}}`, nil},
+		{"not pending", `{"status":{"error":false,"code":200,"type":"success","message":"Success"}}`, ErrMFATokenInvalid},
+		{"rejected", `{"status":{"error":true,"code":401,"type":"Unauthorized","message":"Failed authentication with this factor"}}`, ErrMFATokenInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mux, teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc("/api/1/login/verify_factor", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.body)
+			})
+
+			err := c.verifyFactorPush(context.Background(), "/api/1/login/verify_factor", &verifyFactorParams{DeviceID: "1", StateToken: "state"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got: %v, want: %v", err, tt.want)
+			}
+
+			var errResp *ErrorResponse
+			if tt.want != nil && !errors.As(err, &errResp) {
+				t.Errorf("got: %T, want: *ErrorResponse", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package onelogin
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// Errors returned by the API are classified into one of the following
+// errors, they can be tested with errors.Is. The returned error is still an
+// *ErrorResponse (or a *RateLimitError) that holds the details of the
+// response.
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrMFARequired        = errors.New("MFA is required")
+	ErrMFATokenInvalid    = errors.New("MFA token is invalid")
+	ErrAccountLocked      = errors.New("account is locked")
+	ErrRateLimited        = errors.New("rate limit exceeded")
+	ErrInsufficientScope  = errors.New("insufficient API scope")
+	ErrNotFound           = errors.New("not found")
+	ErrServer             = errors.New("server error")
+	ErrPasswordPolicy     = errors.New("password doesn't meet the password policy")
+	ErrUserActivated      = errors.New("user is already activated")
+)
+
+// A RateLimitError is returned when OneLogin rejects a request because the
+// rate limit of the API credential has been exceeded.
+type RateLimitError struct {
+	*ErrorResponse
+
+	Rate Rate
+}
+
+// Unwrap returns the underlying *ErrorResponse.
+func (e *RateLimitError) Unwrap() error {
+	return e.ErrorResponse
+}
+
+// classifyError maps the HTTP status code and the message of a OneLogin
+// error to one of the exported errors, nil is returned for errors that
+// don't fit any of them. Status codes that tell the cause on their own are
+// checked first, messages are then matched on whole words (e.g., "otp" but
+// not "footprint").
+func classifyError(code int, message string) error {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code >= 500:
+		return ErrServer
+	case code == http.StatusForbidden:
+		return ErrInsufficientScope
+	case code == http.StatusNotFound:
+		return ErrNotFound
+	}
+
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w] = true
+	}
+	msg := strings.Join(strings.Fields(strings.ToLower(message)), " ")
+
+	switch {
+	case strings.Contains(msg, "mfa is required"), strings.Contains(msg, "mfa required"):
+		return ErrMFARequired
+	case words["password"] && (words["policy"] || words["requirement"] || words["requirements"]):
+		return ErrPasswordPolicy
+	case words["locked"]:
+		return ErrAccountLocked
+	case words["factor"], words["otp"]:
+		return ErrMFATokenInvalid
+	case words["credentials"]:
+		return ErrInvalidCredentials
+	case words["scope"], words["permission"]:
+		return ErrInsufficientScope
+	case strings.Contains(msg, "already activated"), strings.Contains(msg, "already active"):
+		return ErrUserActivated
+	case strings.Contains(msg, "not found"):
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// statusError returns the error reported in the body of a response whose
+// HTTP status is a success. If the error can't be classified, it wraps
+// fallback.
+func statusError(r *http.Response, m *responseMessage, fallback error) error {
+	e := &ErrorResponse{
+		Response: r,
+		Code:     m.Status.Code,
+		Type:     m.Status.Type,
+		Message:  m.Status.Message,
+		err:      classifyError(int(m.Status.Code), m.Status.Message),
+	}
+	if e.err == nil {
+		e.err = fallback
+	}
+
+	return e
+}
+
+// A wrappedError is an error with its own message that wraps a classified
+// error, e.g., to report which resource wasn't found.
+type wrappedError struct {
+	msg string
+	err error
+}
+
+func (e *wrappedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the classification of the error.
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+// multiError combines the errors of an operation that carries on after a
+// failure.
+type multiError []error
+
+// err returns nil if there is no error, the only error if there is one, and
+// m otherwise.
+func (m multiError) err() error {
+	switch len(m) {
+	case 0:
+		return nil
+	case 1:
+		return m[0]
+	}
+	return m
+}
+
+func (m multiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches target.
+func (m multiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package onelogin
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		message string
+		want    error
+	}{
+		{"success", 200, "Success", nil},
+		{"invalid credentials", 401, "Authentication Failed: Invalid user credentials", ErrInvalidCredentials},
+		{"invalid token", 401, "Failed authentication with this factor", ErrMFATokenInvalid},
+		{"blank token", 400, "OTP token blank", ErrMFATokenInvalid},
+		{"mfa required", 401, "MFA is required for this user", ErrMFARequired},
+		{"locked account", 401, "Authentication Failed: User is locked", ErrAccountLocked},
+		{"rate limited", 429, "Too many requests", ErrRateLimited},
+		{"insufficient scope", 401, "Insufficient Permission", ErrInsufficientScope},
+		{"not found", 404, "Not Found", ErrNotFound},
+		{"user not found", 400, "User not found", ErrNotFound},
+		{"user activated", 400, "User has already activated their account", ErrUserActivated},
+		{"server error", 502, "Bad Gateway", ErrServer},
+		{"password policy", 422, "Password does not meet the password policy requirements", ErrPasswordPolicy},
+		{"unclassified error", 400, "Content Type is not specified", nil},
+		{"rejected token", 401, "Authorization Information is incorrect", nil},
+		{"missing factor", 404, "Factor not found", ErrNotFound},
+		{"server error mentioning a factor", 500, "Failed to verify factor", ErrServer},
+		{"forbidden", 403, "Forbidden", ErrInsufficientScope},
+		{"unlocked user", 400, "User is already unlocked", nil},
+		{"word containing otp", 400, "Invalid footprint", nil},
+		{"invalid otp", 401, "Invalid OTP", ErrMFATokenInvalid},
+		{"password requirement", 422, "Password must meet the password requirements", ErrPasswordPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "https://api.us.onelogin.com/api/1/users", nil)
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Request:    req,
+				Header:     http.Header{},
+				Body: ioutil.NopCloser(strings.NewReader(
+					`{"status":{"error":true,"code":` + strconv.Itoa(tt.status) + `,"type":"error","message":"` + tt.message + `"}}`)),
+			}
+
+			err := CheckResponse(resp)
+			if tt.status == 200 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var errResp *ErrorResponse
+			if !errors.As(err, &errResp) {
+				t.Fatalf("got: %T, want: *ErrorResponse", err)
+			}
+			if errResp.Message != tt.message {
+				t.Errorf("message got: %v, want: %v", errResp.Message, tt.message)
+			}
+			if got := errors.Unwrap(errResp); got != tt.want {
+				t.Errorf("got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckResponse_rateLimit(t *testing.T) {
+	now = func() time.Time { return time.Unix(1234567890, 0) }
+	defer func() { now = time.Now }()
+
+	req, _ := http.NewRequest("GET", "https://api.us.onelogin.com/api/1/users", nil)
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Request:    req,
+		Header: http.Header{
+			"X-Ratelimit-Limit":     {"5000"},
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {"120"},
+		},
+		Body: ioutil.NopCloser(strings.NewReader(`{}`)),
+	}
+
+	err := CheckResponse(resp)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("got: %v, want: %v", err, ErrRateLimited)
+	}
+
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("got: %T, want: *RateLimitError", err)
+	}
+	want := Rate{Limit: 5000, Remaining: 0, Reset: time.Unix(1234568010, 0)}
+	if rlErr.Rate != want {
+		t.Errorf("got: %+v, want: %+v", rlErr.Rate, want)
+	}
+}
@@ -0,0 +1,181 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EventService deals with OneLogin events, e.g., logins or changes to users.
+// https://developers.onelogin.com/api-docs/1/events/event-resource
+type EventService struct {
+	*service
+}
+
+// An Event is an activity recorded by OneLogin. IDs of resources that don't
+// apply to the event are 0.
+type Event struct {
+	ID                   int64     `json:"id"`
+	CreatedAt            Timestamp `json:"created_at"`
+	AccountID            int64     `json:"account_id"`
+	EventTypeID          int64     `json:"event_type_id"`
+	UserID               int64     `json:"user_id"`
+	UserName             string    `json:"user_name"`
+	ActorUserID          int64     `json:"actor_user_id"`
+	ActorUserName        string    `json:"actor_user_name"`
+	ActorSystem          string    `json:"actor_system"`
+	AssumingActingUserID int64     `json:"assuming_acting_user_id"`
+	AppID                int64     `json:"app_id"`
+	AppName              string    `json:"app_name"`
+	RoleID               int64     `json:"role_id"`
+	RoleName             string    `json:"role_name"`
+	GroupID              int64     `json:"group_id"`
+	GroupName            string    `json:"group_name"`
+	PolicyID             int64     `json:"policy_id"`
+	PolicyName           string    `json:"policy_name"`
+	OTPDeviceID          int64     `json:"otp_device_id"`
+	OTPDeviceName        string    `json:"otp_device_name"`
+	DirectoryID          int64     `json:"directory_id"`
+	DirectorySyncRunID   int64     `json:"directory_sync_run_id"`
+	ClientID             string    `json:"client_id"`
+	ResourceTypeID       int64     `json:"resource_type_id"`
+	OperationName        string    `json:"operation_name"`
+	Resolution           string    `json:"resolution"`
+	IPAddr               string    `json:"ipaddr"`
+	ProxyIP              string    `json:"proxy_ip"`
+	Notes                string    `json:"notes"`
+	CustomMessage        string    `json:"custom_message"`
+	ErrorDescription     string    `json:"error_description"`
+	RiskScore            int64     `json:"risk_score"`
+	RiskReasons          string    `json:"risk_reasons"`
+}
+
+// An EventType describes a type of events.
+type EventType struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// EventListOptions specifies the filters of ListEvents.
+//
+// https://developers.onelogin.com/api-docs/1/events/get-events
+type EventListOptions struct {
+	EventTypeID int64     `url:"event_type_id,omitempty"`
+	UserID      int64     `url:"user_id,omitempty"`
+	ClientID    string    `url:"client_id,omitempty"`
+	DirectoryID int64     `url:"directory_id,omitempty"`
+	Resolution  string    `url:"resolution,omitempty"`
+	Since       time.Time `url:"since,omitempty"`
+	Until       time.Time `url:"until,omitempty"`
+
+	PageOptions `url:"-"`
+}
+
+// ListEvents returns the OneLogin events matching opt. Accounts record many
+// events, consider bounding them with Since and Until or streaming them with
+// ListEventsPages.
+func (s *EventService) ListEvents(ctx context.Context, opt *EventListOptions) ([]*Event, error) {
+	var events []*Event
+	if err := s.ListEventsPages(opt).All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ListEventsPages returns an Iterator over the pages of the OneLogin events
+// matching opt, its items are *Event.
+func (s *EventService) ListEventsPages(opt *EventListOptions) *Iterator {
+	return newListIterator(s.client, "/api/1/events", opt)
+}
+
+// GetEvent returns a OneLogin event specified by its ID.
+//
+// https://developers.onelogin.com/api-docs/1/events/get-event-by-id
+func (s *EventService) GetEvent(ctx context.Context, id int64) (*Event, error) {
+	u := fmt.Sprintf("/api/1/events/%v", id)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.AddAuthorization(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var events []*Event
+	_, err = s.client.Do(ctx, req, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) != 1 {
+		return nil, errors.New("unexpected event response")
+	}
+
+	return events[0], nil
+}
+
+// GetEventTypes returns the types of the OneLogin events.
+//
+// https://developers.onelogin.com/api-docs/1/events/event-types
+func (s *EventService) GetEventTypes(ctx context.Context) ([]*EventType, error) {
+	var types []*EventType
+	it := newIterator(s.client, "/api/1/events/types", nil)
+	if err := it.All(ctx, &types); err != nil {
+		return nil, err
+	}
+
+	return types, nil
+}
+
+// CreateEventRequest holds the fields of a custom event, EventTypeID is
+// required.
+//
+// https://developers.onelogin.com/api-docs/1/events/create-event
+type CreateEventRequest struct {
+	EventTypeID          int64  `json:"event_type_id"`
+	AccountID            int64  `json:"account_id,omitempty"`
+	UserID               int64  `json:"user_id,omitempty"`
+	UserName             string `json:"user_name,omitempty"`
+	ActorUserID          int64  `json:"actor_user_id,omitempty"`
+	ActorUserName        string `json:"actor_user_name,omitempty"`
+	ActorSystem          string `json:"actor_system,omitempty"`
+	AssumingActingUserID int64  `json:"assuming_acting_user_id,omitempty"`
+	AppID                int64  `json:"app_id,omitempty"`
+	AppName              string `json:"app_name,omitempty"`
+	RoleID               int64  `json:"role_id,omitempty"`
+	RoleName             string `json:"role_name,omitempty"`
+	GroupID              int64  `json:"group_id,omitempty"`
+	GroupName            string `json:"group_name,omitempty"`
+	PolicyID             int64  `json:"policy_id,omitempty"`
+	PolicyName           string `json:"policy_name,omitempty"`
+	OTPDeviceID          int64  `json:"otp_device_id,omitempty"`
+	OTPDeviceName        string `json:"otp_device_name,omitempty"`
+	DirectoryID          int64  `json:"directory_id,omitempty"`
+	DirectorySyncRunID   int64  `json:"directory_sync_run_id,omitempty"`
+	ResourceTypeID       int64  `json:"resource_type_id,omitempty"`
+	OperationName        string `json:"operation_name,omitempty"`
+	IPAddr               string `json:"ipaddr,omitempty"`
+	Notes                string `json:"notes,omitempty"`
+	CustomMessage        string `json:"custom_message,omitempty"`
+	ErrorDescription     string `json:"error_description,omitempty"`
+}
+
+// CreateEvent records a custom event, e.g., to audit the actions of a script
+// alongside the ones of OneLogin.
+func (s *EventService) CreateEvent(ctx context.Context, event *CreateEventRequest) error {
+	u := "/api/1/events"
+
+	if event == nil {
+		return errors.New("no event to create")
+	}
+	if event.EventTypeID == 0 {
+		return errors.New("required field is missing: 'event_type_id'")
+	}
+
+	return s.send(ctx, "POST", u, event, nil)
+}
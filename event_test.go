@@ -0,0 +1,92 @@
+package onelogin
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventService_ListEvents(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/events", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got, want := q.Get("event_type_id"), "5"; got != want {
+			t.Errorf("event_type_id got: %v, want: %v", got, want)
+		}
+		if got, want := q.Get("since"), "2019-03-12T00:00:00Z"; got != want {
+			t.Errorf("since got: %v, want: %v", got, want)
+		}
+
+		switch q.Get("after_cursor") {
+		case "":
+			fmt.Fprint(w, `{"status":{"error":false,"code":200},"pagination":{"after_cursor":"next"},"data":[
+				{"id":1,"created_at":"2019-03-12T17:13:54.123Z","event_type_id":5,"user_id":42,"role_id":null}]}`)
+		case "next":
+			fmt.Fprint(w, `{"status":{"error":false,"code":200},"pagination":{"after_cursor":null},"data":[{"id":2,"event_type_id":5}]}`)
+		}
+	})
+
+	events, err := c.Event.ListEvents(context.Background(), &EventListOptions{
+		EventTypeID: 5,
+		Since:       time.Date(2019, 3, 12, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 2 || events[0].ID != 1 || events[1].ID != 2 {
+		t.Fatalf("got: %+v", events)
+	}
+	if want := time.Date(2019, 3, 12, 17, 13, 54, 123000000, time.UTC); !events[0].CreatedAt.Equal(want) {
+		t.Errorf("created at got: %v, want: %v", events[0].CreatedAt, want)
+	}
+}
+
+func TestEventService_GetEventTypes(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/events/types", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[{"id":5,"name":"USER_LOGGED_INTO_ONELOGIN","description":"%user% logged into OneLogin"}]}`)
+	})
+
+	types, err := c.Event.GetEventTypes(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(types) != 1 || types[0].ID != 5 || types[0].Name != "USER_LOGGED_INTO_ONELOGIN" {
+		t.Errorf("got: %+v", types)
+	}
+}
+
+func TestEventService_CreateEvent(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/events", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if got, want := strings.TrimSpace(string(b)), `{"event_type_id":5,"user_id":42,"custom_message":"onboarded"}`; got != want {
+			t.Errorf("body got: %v, want: %v", got, want)
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200,"type":"success","message":"Success"}}`)
+	})
+
+	err := c.Event.CreateEvent(context.Background(), &CreateEventRequest{EventTypeID: 5, UserID: 42, CustomMessage: "onboarded"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := c.Event.CreateEvent(context.Background(), &CreateEventRequest{}); err == nil {
+		t.Error("expected an error for a missing event type")
+	}
+
+	if err := c.Event.CreateEvent(context.Background(), nil); err == nil {
+		t.Error("expected an error for a nil event")
+	}
+}
@@ -1,52 +1,70 @@
 package onelogin
 
-import "golang.org/x/net/context"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 // GroupService deals with OneLogin groups.
 type GroupService struct {
 	*service
 }
 
-// Group contains the ID (immutable) and name (mutable) of a group.
+// Group contains the ID (immutable) and name (mutable) of a group. Each group
+// is assigned a security policy, Reference is an optional external reference
+// to the group.
 type Group struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Reference string `json:"reference"`
 }
 
 // GetGroups returns all the OneLogin groups.
 func (s *GroupService) GetGroups(ctx context.Context) ([]*Group, error) {
-	u := "/api/1/groups"
-
 	var groups []*Group
-	var afterCursor string
-
-	for {
-		uu, err := addOptions(u, &urlQuery{AfterCursor: afterCursor})
-		if err != nil {
-			return nil, err
-		}
-
-		req, err := s.client.NewRequest("GET", uu, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		if err := s.client.AddAuthorization(ctx, req); err != nil {
-			return nil, err
-		}
-
-		var gs []*Group
-		resp, err := s.client.Do(ctx, req, &gs)
-		if err != nil {
-			return nil, err
-		}
-		groups = append(groups, gs...)
-		if resp.PaginationAfterCursor == nil {
-			break
-		}
-
-		afterCursor = *resp.PaginationAfterCursor
+	if err := s.Pages(nil).All(ctx, &groups); err != nil {
+		return nil, err
 	}
 
 	return groups, nil
 }
+
+// Pages returns an Iterator over the pages of OneLogin groups, its items are
+// *Group.
+func (s *GroupService) Pages(opt *PageOptions) *Iterator {
+	return newIterator(s.client, "/api/1/groups", opt)
+}
+
+// GetGroup returns a OneLogin group specified by its ID.
+//
+// https://developers.onelogin.com/api-docs/1/groups/get-group-by-id
+func (s *GroupService) GetGroup(ctx context.Context, id int64) (*Group, error) {
+	u := fmt.Sprintf("/api/1/groups/%v", id)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.AddAuthorization(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var groups []*Group
+	_, err = s.client.Do(ctx, req, &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) != 1 {
+		return nil, errors.New("unexpected group response")
+	}
+
+	return groups[0], nil
+}
+
+// GetGroupUsers returns the OneLogin users belonging to a group.
+func (s *GroupService) GetGroupUsers(ctx context.Context, id int64) ([]*User, error) {
+	return s.client.User.ListUsers(ctx, &UserListOptions{GroupID: id})
+}
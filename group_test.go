@@ -0,0 +1,45 @@
+package onelogin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGroupService_GetGroup(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/groups/7", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[{"id":7,"name":"Contractors","reference":"ext-7"}]}`)
+	})
+
+	group, err := c.Group.GetGroup(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (Group{ID: 7, Name: "Contractors", Reference: "ext-7"}); *group != want {
+		t.Errorf("got: %+v, want: %+v", group, want)
+	}
+}
+
+func TestGroupService_GetGroupUsers(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("group_id"), "7"; got != want {
+			t.Errorf("group_id got: %v, want: %v", got, want)
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[{"id":1,"group_id":7}]}`)
+	})
+
+	users, err := c.Group.GetGroupUsers(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 1 || users[0].GroupID != 7 {
+		t.Errorf("got: %+v", users)
+	}
+}
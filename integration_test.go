@@ -312,7 +312,7 @@ func TestGenerateSAMLAssertion(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := c.SAMLService.GenerateSAMLAssertion(context.Background(), tt.username, tt.password, tt.appID, "")
+			_, err := c.SAML.GenerateSAMLAssertion(context.Background(), tt.username, tt.password, tt.appID, "")
 			if err != nil {
 				t.Fatalf("error generating SAML assertion: %v", err)
 			}
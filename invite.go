@@ -0,0 +1,61 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+)
+
+// InviteService handles the invitation of users to activate their account.
+// https://developers.onelogin.com/api-docs/1/invite-links/overview
+type InviteService struct {
+	*service
+}
+
+type inviteLinkParams struct {
+	Email         string `json:"email"`
+	PersonalEmail string `json:"personal_email,omitempty"`
+}
+
+// GenerateInviteLink returns the link a user opens to set their password and
+// activate their account. ErrNotFound is returned if no user has the email,
+// ErrUserActivated if the user has already been activated.
+//
+// https://developers.onelogin.com/api-docs/1/invite-links/generate-invite-link
+func (s *InviteService) GenerateInviteLink(ctx context.Context, email string) (string, error) {
+	u := "/api/1/invites/get_invite_link"
+
+	if email == "" {
+		return "", errors.New("required field is missing: 'email'")
+	}
+
+	var links []string
+	if err := s.send(ctx, "POST", u, inviteLinkParams{Email: email}, &links); err != nil {
+		return "", err
+	}
+
+	if len(links) != 1 {
+		return "", errors.New("unexpected invite link response")
+	}
+
+	return links[0], nil
+}
+
+// SendInviteLink emails the invite link to a user. If personalEmail isn't
+// empty, the link is sent to it instead of the email of the user, e.g., for
+// new hires whose mailbox doesn't exist yet. The errors are the ones of
+// GenerateInviteLink.
+//
+// https://developers.onelogin.com/api-docs/1/invite-links/send-invite-link
+func (s *InviteService) SendInviteLink(ctx context.Context, email, personalEmail string) error {
+	u := "/api/1/invites/send_invite_link"
+
+	if email == "" {
+		return errors.New("required field is missing: 'email'")
+	}
+
+	b := inviteLinkParams{
+		Email:         email,
+		PersonalEmail: personalEmail,
+	}
+	return s.send(ctx, "POST", u, b, nil)
+}
@@ -0,0 +1,47 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestInviteService_GenerateInviteLink(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/invites/get_invite_link", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if got, want := strings.TrimSpace(string(b)), `{"email":"jane@example.com"}`; got != want {
+			t.Errorf("body got: %v, want: %v", got, want)
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200,"type":"success","message":"Success"},"data":["https://myteam.onelogin.com/password/abc"]}`)
+	})
+
+	link, err := c.Invite.GenerateInviteLink(context.Background(), "jane@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "https://myteam.onelogin.com/password/abc"; link != want {
+		t.Errorf("got: %v, want: %v", link, want)
+	}
+}
+
+func TestInviteService_SendInviteLink_notFound(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/invites/send_invite_link", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"status":{"error":true,"code":404,"type":"not found","message":"User not found"}}`)
+	})
+
+	err := c.Invite.SendInviteLink(context.Background(), "jane@example.com", "jane@gmail.com")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("got: %v, want: %v", err, ErrNotFound)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 )
 
 // LoginService handles communications with login pages.
@@ -147,13 +146,9 @@ func (s *LoginService) AuthenticateWithPushVerify(ctx context.Context, emailOrUs
 		DoNotNotify: false,
 	}
 
-	m, err := s.client.verifyFactor(ctx, u, p)
-	if err != nil {
+	if err := s.client.verifyFactorPush(ctx, u, p); err != nil {
 		return nil, err
 	}
-	if m.Status.Type != "pending" {
-		return nil, fmt.Errorf("verify factor failed, unexpected status = %v", m.Status.Type)
-	}
 
 	return auth, nil
 }
@@ -0,0 +1,154 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MFAService handles the administration of the MFA factors of users, e.g.,
+// to reset a lost authenticator.
+// https://developers.onelogin.com/api-docs/1/multi-factor-authentication/overview
+type MFAService struct {
+	*service
+}
+
+// AuthFactor is an MFA factor a user can enroll.
+type AuthFactor struct {
+	Name     string `json:"name"`
+	FactorID int64  `json:"factor_id"`
+}
+
+// OTPDevice is an MFA device enrolled by a user.
+type OTPDevice struct {
+	ID              int64  `json:"id"`
+	Active          bool   `json:"active"`
+	Default         bool   `json:"default"`
+	AuthFactorName  string `json:"auth_factor_name"`
+	TypeDisplayName string `json:"type_display_name"`
+	UserDisplayName string `json:"user_display_name"`
+	PhoneNumber     string `json:"phone_number,omitempty"`
+	// NeedsTrigger is true if an OTP must be sent to the device (e.g., SMS)
+	// before it can be verified, see ActivateFactor.
+	NeedsTrigger bool   `json:"needs_trigger"`
+	StateToken   string `json:"state_token,omitempty"`
+}
+
+// GetAvailableFactors returns the MFA factors a user can enroll.
+//
+// https://developers.onelogin.com/api-docs/1/multi-factor-authentication/available-factors
+func (s *MFAService) GetAvailableFactors(ctx context.Context, userID int64) ([]*AuthFactor, error) {
+	u := fmt.Sprintf("/api/1/users/%v/auth_factors", userID)
+
+	var r struct {
+		AuthFactors []*AuthFactor `json:"auth_factors"`
+	}
+	if err := s.send(ctx, "GET", u, nil, &r); err != nil {
+		return nil, err
+	}
+
+	return r.AuthFactors, nil
+}
+
+// GetEnrolledDevices returns the MFA devices enrolled by a user.
+//
+// https://developers.onelogin.com/api-docs/1/multi-factor-authentication/enrolled-factors
+func (s *MFAService) GetEnrolledDevices(ctx context.Context, userID int64) ([]*OTPDevice, error) {
+	u := fmt.Sprintf("/api/1/users/%v/otp_devices", userID)
+
+	var r struct {
+		OTPDevices []*OTPDevice `json:"otp_devices"`
+	}
+	if err := s.send(ctx, "GET", u, nil, &r); err != nil {
+		return nil, err
+	}
+
+	return r.OTPDevices, nil
+}
+
+// EnrollFactorRequest holds the details of an MFA factor to enroll.
+type EnrollFactorRequest struct {
+	FactorID    int64  `json:"factor_id"`
+	DisplayName string `json:"display_name"`
+	// Number is the phone number of SMS factors.
+	Number string `json:"number,omitempty"`
+	// Verified enrolls the device as already verified, it is then active
+	// right away.
+	Verified bool `json:"verified,omitempty"`
+}
+
+// EnrollFactor enrolls an MFA device for a user. Unless it is enrolled as
+// verified, the device must then be verified with VerifyEnrollment.
+//
+// https://developers.onelogin.com/api-docs/1/multi-factor-authentication/enroll-factor
+func (s *MFAService) EnrollFactor(ctx context.Context, userID int64, factor *EnrollFactorRequest) (*OTPDevice, error) {
+	u := fmt.Sprintf("/api/1/users/%v/otp_devices", userID)
+
+	if factor == nil {
+		return nil, errors.New("no factor to enroll")
+	}
+	if factor.FactorID == 0 {
+		return nil, errors.New("required field is missing: 'factor_id'")
+	}
+
+	var devices []*OTPDevice
+	if err := s.send(ctx, "POST", u, factor, &devices); err != nil {
+		return nil, err
+	}
+
+	if len(devices) != 1 {
+		return nil, errors.New("unexpected enrollment response")
+	}
+
+	return devices[0], nil
+}
+
+// ActivateFactor sends an OTP to an enrolled device that needs to be
+// triggered (e.g., SMS or push), so that the enrollment can be verified. The
+// returned device holds the StateToken to pass to VerifyEnrollment.
+//
+// https://developers.onelogin.com/api-docs/1/multi-factor-authentication/activate-factor
+func (s *MFAService) ActivateFactor(ctx context.Context, userID, deviceID int64) (*OTPDevice, error) {
+	u := fmt.Sprintf("/api/1/users/%v/otp_devices/%v/trigger", userID, deviceID)
+
+	var devices []*OTPDevice
+	if err := s.send(ctx, "POST", u, nil, &devices); err != nil {
+		return nil, err
+	}
+
+	if len(devices) != 1 {
+		return nil, errors.New("unexpected activation response")
+	}
+
+	return devices[0], nil
+}
+
+type verifyEnrollmentParams struct {
+	OTPToken   string `json:"otp_token"`
+	StateToken string `json:"state_token,omitempty"`
+}
+
+// VerifyEnrollment verifies the enrollment of an MFA device with an OTP
+// generated by the device, which activates it. stateToken is the one returned
+// by ActivateFactor for triggered devices, it is empty otherwise.
+//
+// https://developers.onelogin.com/api-docs/1/multi-factor-authentication/verify-factor
+func (s *MFAService) VerifyEnrollment(ctx context.Context, userID, deviceID int64, otp, stateToken string) error {
+	u := fmt.Sprintf("/api/1/users/%v/otp_devices/%v/verify", userID, deviceID)
+
+	p := verifyEnrollmentParams{
+		OTPToken:   otp,
+		StateToken: stateToken,
+	}
+
+	return s.send(ctx, "POST", u, p, nil)
+}
+
+// RemoveDevice removes an MFA device enrolled by a user.
+//
+// https://developers.onelogin.com/api-docs/1/multi-factor-authentication/remove-factor
+func (s *MFAService) RemoveDevice(ctx context.Context, userID, deviceID int64) error {
+	u := fmt.Sprintf("/api/1/users/%v/otp_devices/%v", userID, deviceID)
+
+	return s.send(ctx, "DELETE", u, nil, nil)
+}
@@ -0,0 +1,142 @@
+package onelogin
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMFAService_GetEnrolledDevices(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/1/otp_devices", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":{"otp_devices":[
+			{"id":42,"active":true,"default":true,"auth_factor_name":"Google Authenticator","type_display_name":"Google Authenticator","user_display_name":"phone","needs_trigger":false}]}}`)
+	})
+
+	devices, err := c.MFA.GetEnrolledDevices(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(devices) != 1 || devices[0].ID != 42 || devices[0].AuthFactorName != "Google Authenticator" {
+		t.Errorf("got: %+v", devices)
+	}
+}
+
+func TestMFAService_EnrollFactor(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/1/otp_devices", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if got, want := strings.TrimSpace(string(b)), `{"factor_id":7,"display_name":"phone","number":"+15551234567"}`; got != want {
+			t.Errorf("body got: %v, want: %v", got, want)
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[{"id":42,"active":false,"needs_trigger":true}]}`)
+	})
+
+	if _, err := c.MFA.EnrollFactor(context.Background(), 1, nil); err == nil {
+		t.Error("expected error for a nil factor, got nil")
+	}
+
+	device, err := c.MFA.EnrollFactor(context.Background(), 1, &EnrollFactorRequest{FactorID: 7, DisplayName: "phone", Number: "+15551234567"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if device.ID != 42 || !device.NeedsTrigger {
+		t.Errorf("got: %+v", device)
+	}
+}
+
+func TestMFAService_GetAvailableFactors(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/1/auth_factors", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":{"auth_factors":[{"name":"OneLogin SMS","factor_id":7}]}}`)
+	})
+
+	factors, err := c.MFA.GetAvailableFactors(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(factors) != 1 || *factors[0] != (AuthFactor{Name: "OneLogin SMS", FactorID: 7}) {
+		t.Errorf("got: %+v", factors)
+	}
+}
+
+func TestMFAService_ActivateFactor(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/1/otp_devices/42/trigger", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("method got: %v, want: POST", r.Method)
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[{"id":42,"active":false,"needs_trigger":true,"state_token":"state"}]}`)
+	})
+
+	device, err := c.MFA.ActivateFactor(context.Background(), 1, 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if device.ID != 42 || device.StateToken != "state" {
+		t.Errorf("got: %+v", device)
+	}
+}
+
+func TestMFAService_VerifyEnrollment(t *testing.T) {
+	tests := []struct {
+		name       string
+		stateToken string
+		want       string
+	}{
+		{"authenticator", "", `{"otp_token":"123456"}`},
+		{"triggered", "state", `{"otp_token":"123456","state_token":"state"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mux, teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc("/api/1/users/1/otp_devices/42/verify", func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				if got := strings.TrimSpace(string(b)); got != tt.want {
+					t.Errorf("body got: %v, want: %v", got, tt.want)
+				}
+				fmt.Fprint(w, `{"status":{"error":false,"code":200,"type":"success","message":"Success"}}`)
+			})
+
+			if err := c.MFA.VerifyEnrollment(context.Background(), 1, 42, "123456", tt.stateToken); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMFAService_RemoveDevice(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	var removed bool
+	mux.HandleFunc("/api/1/users/1/otp_devices/42", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("method got: %v, want: DELETE", r.Method)
+		}
+		removed = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if err := c.MFA.RemoveDevice(context.Background(), 1, 42); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !removed {
+		t.Error("the device wasn't removed")
+	}
+}
@@ -2,7 +2,9 @@ package onelogin
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 )
 
@@ -55,8 +57,22 @@ func (t *oauthToken) isNearExpired() bool {
 	return now().UTC().After(t.CreatedAt.UTC().Add(time.Second * time.Duration(t.ExpiresIn-60)))
 }
 
-// refresh the token. The current token gets updates with new valid values.
-func (t *oauthToken) refresh(ctx context.Context) error {
+// isRefreshDue reports whether the token should be refreshed in the
+// background, ahead of isNearExpired: within 5 minutes of its expiration, or
+// within the last tenth of its lifetime for short-lived tokens.
+func (t *oauthToken) isRefreshDue() bool {
+	lifetime := time.Second * time.Duration(t.ExpiresIn)
+	ahead := lifetime / 10
+	if ahead > 5*time.Minute {
+		ahead = 5 * time.Minute
+	}
+
+	// TimeNow <AFTER> TokenTimeCreated + (TokenLifetime - ahead) ?
+	return now().UTC().After(t.CreatedAt.UTC().Add(lifetime - ahead))
+}
+
+// refresh the token. A new token is returned, t is left untouched.
+func (t *oauthToken) refresh(ctx context.Context) (*oauthToken, error) {
 	u := "/auth/oauth2/token"
 	b := issueTokenParams{
 		GrantType:    "refresh_token",
@@ -65,24 +81,16 @@ func (t *oauthToken) refresh(ctx context.Context) error {
 	}
 	req, err := t.client.NewRequest("POST", u, b)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var r []getTokenResponse
 	_, err = t.client.Do(ctx, req, &r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	createdAt, _ := time.Parse(time.RFC3339Nano, r[0].CreatedAt)
-	t.AccessToken = r[0].AccessToken
-	t.AccountID = r[0].AccountID
-	t.CreatedAt = createdAt
-	t.ExpiresIn = r[0].ExpiresIn
-	t.TokenType = r[0].TokenType
-	t.refreshToken = r[0].RefreshToken
-
-	return nil
+	return newTokenFromResponse(t.client, r)
 }
 
 // getToken issues a new token.
@@ -96,7 +104,7 @@ func (s *OauthService) getToken(ctx context.Context) (*oauthToken, error) {
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", fmt.Sprintf("client_id: %s, client_secret: %s", s.client.clientID, s.client.clientSecret))
+	s.setCredentials(req)
 
 	var r []getTokenResponse
 	_, err = s.client.Do(ctx, req, &r)
@@ -104,7 +112,26 @@ func (s *OauthService) getToken(ctx context.Context) (*oauthToken, error) {
 		return nil, err
 	}
 
-	createdAt, _ := time.Parse(time.RFC3339Nano, r[0].CreatedAt)
+	return newTokenFromResponse(s.client, r)
+}
+
+// newTokenFromResponse validates the response of the token endpoint and
+// returns the token it holds. If the response doesn't tell when the token
+// was created, the local clock is used.
+func newTokenFromResponse(c *Client, r []getTokenResponse) (*oauthToken, error) {
+	if len(r) != 1 || r[0].AccessToken == "" || r[0].ExpiresIn <= 0 {
+		return nil, errors.New("unexpected token response")
+	}
+
+	createdAt := now()
+	if r[0].CreatedAt != "" {
+		var err error
+		createdAt, err = time.Parse(time.RFC3339Nano, r[0].CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected token creation time: %v", err)
+		}
+	}
+
 	token := &oauthToken{
 		AccessToken:  r[0].AccessToken,
 		AccountID:    r[0].AccountID,
@@ -112,8 +139,73 @@ func (s *OauthService) getToken(ctx context.Context) (*oauthToken, error) {
 		ExpiresIn:    r[0].ExpiresIn,
 		TokenType:    r[0].TokenType,
 		refreshToken: r[0].RefreshToken,
-		client:       s.client,
+		client:       c,
 	}
 
 	return token, nil
 }
+
+// setCredentials sets the API credential of the client as the Authorization
+// header of the request.
+func (s *OauthService) setCredentials(req *http.Request) {
+	req.Header.Set("Authorization", fmt.Sprintf("client_id: %s, client_secret: %s", s.client.clientID, s.client.clientSecret))
+}
+
+type revokeTokenParams struct {
+	AccessToken string `json:"access_token"`
+}
+
+// RevokeToken revokes an access token issued to the API credential of the
+// client.
+//
+// https://developers.onelogin.com/api-docs/1/oauth20-tokens/revoke-tokens
+func (s *OauthService) RevokeToken(ctx context.Context, accessToken string) error {
+	u := "/auth/oauth2/revoke"
+
+	b := revokeTokenParams{
+		AccessToken: accessToken,
+	}
+	req, err := s.client.NewRequest("POST", u, b)
+	if err != nil {
+		return err
+	}
+	s.setCredentials(req)
+
+	_, err = s.client.Do(ctx, req, nil)
+	return err
+}
+
+type getRateLimitResponse struct {
+	Limit     int   `json:"X-RateLimit-Limit"`
+	Remaining int   `json:"X-RateLimit-Remaining"`
+	Reset     int64 `json:"X-RateLimit-Reset"`
+}
+
+// GetRateLimit returns the current rate limit of the API credential, without
+// consuming it.
+//
+// https://developers.onelogin.com/api-docs/1/oauth20-tokens/get-rate-limit
+func (s *OauthService) GetRateLimit(ctx context.Context) (*Rate, error) {
+	u := "/auth/rate_limit"
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.AddAuthorization(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var r getRateLimitResponse
+	_, err = s.client.Do(ctx, req, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rate{
+		Limit:     r.Limit,
+		Remaining: r.Remaining,
+		Reset:     now().Add(time.Second * time.Duration(r.Reset)),
+	}, nil
+}
@@ -1,6 +1,13 @@
 package onelogin
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 )
@@ -110,3 +117,324 @@ func TestIsNearExpired(t *testing.T) {
 		})
 	}
 }
+
+func TestOauthService_GetRateLimit(t *testing.T) {
+	now = func() time.Time { return time.Unix(1234567890, 0) }
+	defer func() { now = time.Now }()
+
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/auth/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "bearer:token" {
+			t.Errorf("Authorization got: %v, want: %v", got, "bearer:token")
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200,"type":"success","message":"Success"},
+			"data":{"X-RateLimit-Limit":5000,"X-RateLimit-Remaining":4992,"X-RateLimit-Reset":1204}}`)
+	})
+
+	got, err := c.Oauth.GetRateLimit(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &Rate{Limit: 5000, Remaining: 4992, Reset: time.Unix(1234569094, 0)}
+	if *got != *want {
+		t.Errorf("got: %+v, want: %+v", got, want)
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	var revoked string
+	mux.HandleFunc("/auth/oauth2/revoke", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "client_id: clientID, client_secret: clientSecret" {
+			t.Errorf("Authorization got: %v", got)
+		}
+		var b revokeTokenParams
+		json.NewDecoder(r.Body).Decode(&b)
+		revoked = b.AccessToken
+		fmt.Fprint(w, `{"status":{"error":false,"code":200,"type":"success","message":"Success"}}`)
+	})
+
+	// no token has been issued yet, nothing to revoke
+	if err := c.Close(context.Background()); err != nil || revoked != "" {
+		t.Fatalf("got: %v, %q, want: nil, \"\"", err, revoked)
+	}
+
+	req, _ := c.NewRequest("GET", "/api/1/test", nil)
+	if err := c.AddAuthorization(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revoked != "token" {
+		t.Errorf("got: %v, want: %v", revoked, "token")
+	}
+	if c.oauthToken != nil {
+		t.Error("the revoked token is still in use")
+	}
+}
+
+func TestAddAuthorization_refreshFallback(t *testing.T) {
+	now = func() time.Time { return time.Unix(1234567890, 0) }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name       string
+		code       int
+		wantGrants string
+		wantErr    bool
+	}{
+		{"rejected refresh token", http.StatusUnauthorized, "[refresh_token client_credentials]", false},
+		{"invalid grant", http.StatusBadRequest, "[refresh_token client_credentials]", false},
+		{"rate limited", http.StatusTooManyRequests, "[refresh_token]", true},
+		{"server error", http.StatusInternalServerError, "[refresh_token]", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var grants []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var b issueTokenParams
+				json.NewDecoder(r.Body).Decode(&b)
+				grants = append(grants, b.GrantType)
+
+				if b.GrantType == "refresh_token" {
+					w.WriteHeader(tt.code)
+					fmt.Fprintf(w, `{"status":{"error":true,"code":%d,"type":"Error","message":"refresh failed"}}`, tt.code)
+					return
+				}
+				// a token without created_at
+				fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[{"access_token":"new","expires_in":36000}]}`)
+			}))
+			defer server.Close()
+
+			c, err := NewWithOptions(WithCredentials("clientID", "clientSecret"), WithBaseURL(server.URL), WithRetryPolicy(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.oauthToken = &oauthToken{AccessToken: "old", CreatedAt: time.Unix(1234567000, 0), ExpiresIn: 900, client: c}
+
+			req, _ := c.NewRequest("GET", "/api/1/test", nil)
+			err = c.AddAuthorization(context.Background(), req)
+			if got := fmt.Sprint(grants); got != tt.wantGrants {
+				t.Errorf("grants got: %v, want: %v", got, tt.wantGrants)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := req.Header.Get("Authorization"); got != "bearer:new" {
+				t.Errorf("got: %v, want: %v", got, "bearer:new")
+			}
+			if !c.oauthToken.CreatedAt.Equal(now()) {
+				t.Errorf("created at got: %v, want: %v", c.oauthToken.CreatedAt, now())
+			}
+		})
+	}
+}
+
+func TestNewTokenFromResponse(t *testing.T) {
+	now = func() time.Time { return time.Unix(1234567890, 0) }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name          string
+		response      getTokenResponse
+		wantCreatedAt time.Time
+		wantErr       bool
+	}{
+		{"created at", getTokenResponse{AccessToken: "token", CreatedAt: "2015-11-11T03:36:18.714Z", ExpiresIn: 36000}, time.Date(2015, 11, 11, 3, 36, 18, 714000000, time.UTC), false},
+		{"no created at", getTokenResponse{AccessToken: "token", ExpiresIn: 36000}, time.Unix(1234567890, 0), false},
+		{"invalid created at", getTokenResponse{AccessToken: "token", CreatedAt: "yesterday", ExpiresIn: 36000}, time.Time{}, true},
+		{"no access token", getTokenResponse{ExpiresIn: 36000}, time.Time{}, true},
+		{"no expiry", getTokenResponse{AccessToken: "token"}, time.Time{}, true},
+		{"negative expiry", getTokenResponse{AccessToken: "token", ExpiresIn: -1}, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := newTokenFromResponse(nil, []getTokenResponse{tt.response})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got token: %+v", token)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !token.CreatedAt.Equal(tt.wantCreatedAt) {
+				t.Errorf("created at got: %v, want: %v", token.CreatedAt, tt.wantCreatedAt)
+			}
+		})
+	}
+}
+
+func TestClientDo_unauthorized(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantCalls int
+		wantErr   bool
+	}{
+		{"rejected token", "Authorization Information is incorrect", 2, false},
+		{"invalid credentials", "Authentication Failed: Invalid user credentials", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mux, teardown := setup()
+			defer teardown()
+
+			var calls int
+			mux.HandleFunc("/api/1/test", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					w.WriteHeader(http.StatusUnauthorized)
+					fmt.Fprintf(w, `{"status":{"error":true,"code":401,"type":"Unauthorized","message":%q}}`, tt.message)
+					return
+				}
+				fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+			})
+
+			req, _ := c.NewRequest("POST", "/api/1/test", map[string]string{"a": "b"})
+			if err := c.AddAuthorization(context.Background(), req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err := c.Do(context.Background(), req, nil)
+			if !tt.wantErr && (err != nil) {
+				t.Errorf("no error expected, got: %v", err)
+			}
+			if tt.wantErr && (err == nil) {
+				t.Error("expected error, got nil")
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls got: %v, want: %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_Close_refreshInFlight(t *testing.T) {
+	now = time.Now
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/oauth2/token":
+			<-release
+			fmt.Fprintf(w, `{"status":{"error":false,"code":200},"data":[{"access_token":"new","created_at":%q,"expires_in":36000}]}`,
+				time.Now().UTC().Format(time.RFC3339Nano))
+		case "/auth/oauth2/revoke":
+			var b revokeTokenParams
+			json.NewDecoder(r.Body).Decode(&b)
+			mu.Lock()
+			revoked = append(revoked, b.AccessToken)
+			mu.Unlock()
+			fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	c, err := NewWithOptions(WithCredentials("clientID", "clientSecret"), WithBaseURL(server.URL), WithTokenStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the token is due for a background refresh
+	c.oauthToken = &oauthToken{AccessToken: "old", CreatedAt: time.Now().Add(-58 * time.Minute), ExpiresIn: 3600, client: c}
+
+	req, _ := c.NewRequest("GET", "/api/1/test", nil)
+	if err := c.AddAuthorization(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- c.Close(context.Background()) }()
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := fmt.Sprint(revoked); got != "[new old]" {
+		t.Errorf("revoked got: %v, want: [new old]", got)
+	}
+	if c.oauthToken != nil {
+		t.Error("a token is still in use")
+	}
+	if saved, _ := store.Load(context.Background(), "clientID"); saved != nil {
+		t.Errorf("a token is still stored: %+v", saved)
+	}
+}
+
+func TestClient_Close_revokeFailure(t *testing.T) {
+	now = time.Now
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/oauth2/token":
+			<-release
+			fmt.Fprintf(w, `{"status":{"error":false,"code":200},"data":[{"access_token":"new","created_at":%q,"expires_in":36000}]}`,
+				time.Now().UTC().Format(time.RFC3339Nano))
+		case "/auth/oauth2/revoke":
+			var b revokeTokenParams
+			json.NewDecoder(r.Body).Decode(&b)
+			mu.Lock()
+			revoked = append(revoked, b.AccessToken)
+			mu.Unlock()
+			if b.AccessToken == "new" {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"status":{"error":true,"code":500,"type":"Internal Server Error","message":"Something went wrong"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	c, err := NewWithOptions(WithCredentials("clientID", "clientSecret"), WithBaseURL(server.URL), WithTokenStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the token is due for a background refresh
+	c.oauthToken = &oauthToken{AccessToken: "old", CreatedAt: time.Now().Add(-58 * time.Minute), ExpiresIn: 3600, client: c}
+
+	req, _ := c.NewRequest("GET", "/api/1/test", nil)
+	if err := c.AddAuthorization(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- c.Close(context.Background()) }()
+	close(release)
+	if err := <-done; !errors.Is(err, ErrServer) {
+		t.Fatalf("got: %v, want: %v", err, ErrServer)
+	}
+
+	// the old token is revoked despite the failure
+	if got := fmt.Sprint(revoked); got != "[new old]" {
+		t.Errorf("revoked got: %v, want: [new old]", got)
+	}
+	if saved, _ := store.Load(context.Background(), "clientID"); saved != nil {
+		t.Errorf("a token is still stored: %+v", saved)
+	}
+}
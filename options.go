@@ -0,0 +1,207 @@
+package onelogin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// An Option configures a Client created by NewWithOptions.
+type Option func(*options) error
+
+// options holds the configuration collected from the Options.
+type options struct {
+	clientID     string
+	clientSecret string
+	subdomain    string
+	region       string
+	baseURL      *url.URL
+	httpClient   *http.Client
+	timeout      time.Duration
+	userAgent    string
+	retryPolicy  *RetryPolicy
+	limiter      Limiter
+	tokenSource  TokenSource
+	tokenStore   TokenStore
+	roleCacheTTL time.Duration
+}
+
+var regionRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// NewWithOptions returns a new OneLogin client configured by opts. Unlike
+// New, the configuration is validated and an error is returned if it is
+// invalid. Credentials are required unless a TokenSource is provided, the
+// region defaults to "us".
+//
+//	c, err := onelogin.NewWithOptions(
+//		onelogin.WithCredentials(clientID, clientSecret),
+//		onelogin.WithRegion("eu"),
+//		onelogin.WithSubdomain("myteam"),
+//		onelogin.WithTimeout(30*time.Second),
+//	)
+func NewWithOptions(opts ...Option) (*Client, error) {
+	o := &options{
+		region:      "us",
+		httpClient:  http.DefaultClient,
+		retryPolicy: DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.tokenSource == nil && (o.clientID == "" || o.clientSecret == "") {
+		return nil, errors.New("client ID and client secret are required")
+	}
+
+	if o.baseURL == nil {
+		u, err := url.Parse(buildURL(baseURL, o.region))
+		if err != nil {
+			return nil, err
+		}
+		o.baseURL = u
+	}
+
+	if o.timeout > 0 {
+		// copy the client, it may be shared (e.g., http.DefaultClient)
+		hc := *o.httpClient
+		hc.Timeout = o.timeout
+		o.httpClient = &hc
+	}
+
+	return newClient(o), nil
+}
+
+// WithCredentials sets the API credentials of the client.
+func WithCredentials(clientID, clientSecret string) Option {
+	return func(o *options) error {
+		o.clientID = clientID
+		o.clientSecret = clientSecret
+		return nil
+	}
+}
+
+// WithSubdomain sets the OneLogin subdomain (i.e., the team name) used to
+// authenticate users.
+func WithSubdomain(subdomain string) Option {
+	return func(o *options) error {
+		o.subdomain = subdomain
+		return nil
+	}
+}
+
+// WithRegion sets the shard of the OneLogin API, e.g., "us" or "eu". It is
+// ignored if WithBaseURL is used.
+func WithRegion(region string) Option {
+	return func(o *options) error {
+		if !regionRegexp.MatchString(region) {
+			return fmt.Errorf("invalid region: %q", region)
+		}
+		o.region = region
+		return nil
+	}
+}
+
+// WithBaseURL sets the base URL of the OneLogin API, for custom shards or to
+// use a fake server in tests. The API paths are absolute, so the base URL
+// can't have a path prefix.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) error {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid base URL: %q", baseURL)
+		}
+		if u.Path != "" && u.Path != "/" {
+			return fmt.Errorf("base URL can't have a path: %q", baseURL)
+		}
+		o.baseURL = u
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send requests, e.g., to
+// configure proxies or mTLS transports.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) error {
+		if client == nil {
+			return errors.New("HTTP client is nil")
+		}
+		o.httpClient = client
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of every HTTP request. The HTTP client is
+// copied, the client passed to WithHTTPClient is left untouched.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid timeout: %v", timeout)
+		}
+		o.timeout = timeout
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) error {
+		o.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the client, nil disables retries.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(o *options) error {
+		o.retryPolicy = p
+		return nil
+	}
+}
+
+// WithRateLimiter sets the Limiter pacing the requests of the client.
+func WithRateLimiter(l Limiter) Option {
+	return func(o *options) error {
+		o.limiter = l
+		return nil
+	}
+}
+
+// WithTokenSource sets the source of the tokens that authorize requests. The
+// client doesn't issue tokens itself anymore.
+func WithTokenSource(ts TokenSource) Option {
+	return func(o *options) error {
+		o.tokenSource = ts
+		return nil
+	}
+}
+
+// WithTokenStore sets the store where the tokens issued by the client are
+// persisted, so that other clients of the same API credential (e.g., in
+// other processes) reuse them.
+func WithTokenStore(store TokenStore) Option {
+	return func(o *options) error {
+		o.tokenStore = store
+		return nil
+	}
+}
+
+// WithRoleCache enables the cache of the role IDs resolved by
+// RoleService.RoleID. The role names are loaded at once and kept for ttl,
+// or until RoleService.InvalidateCache is called.
+func WithRoleCache(ttl time.Duration) Option {
+	return func(o *options) error {
+		if ttl <= 0 {
+			return fmt.Errorf("invalid role cache TTL: %v", ttl)
+		}
+		o.roleCacheTTL = ttl
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package onelogin
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	creds := WithCredentials("clientID", "clientSecret")
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantURL string
+		wantErr bool
+	}{
+		{"default region", []Option{creds}, "https://api.us.onelogin.com/", false},
+		{"eu region", []Option{creds, WithRegion("eu")}, "https://api.eu.onelogin.com/", false},
+		{"base url", []Option{creds, WithRegion("eu"), WithBaseURL("http://localhost:8080/")}, "http://localhost:8080/", false},
+		{"missing credentials", nil, "", true},
+		{"invalid region", []Option{creds, WithRegion("us.evil.com/")}, "", true},
+		{"invalid base url", []Option{creds, WithBaseURL("localhost:8080")}, "", true},
+		{"base url with a path", []Option{creds, WithBaseURL("https://proxy.example.com/onelogin/")}, "", true},
+		{"nil http client", []Option{creds, WithHTTPClient(nil)}, "", true},
+		{"negative timeout", []Option{creds, WithTimeout(-time.Second)}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewWithOptions(tt.opts...)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("no error expected, got: %v", err)
+			}
+
+			if got := c.BaseURL.String(); got != tt.wantURL {
+				t.Errorf("got: %v, want: %v", got, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	hc := &http.Client{}
+	c, err := NewWithOptions(
+		WithCredentials("clientID", "clientSecret"),
+		WithHTTPClient(hc),
+		WithTimeout(time.Second),
+	)
+	if err != nil {
+		t.Fatalf("no error expected, got: %v", err)
+	}
+
+	if c.client.Timeout != time.Second {
+		t.Errorf("got: %v, want: %v", c.client.Timeout, time.Second)
+	}
+	if hc.Timeout != 0 {
+		t.Errorf("the HTTP client passed as an option was modified")
+	}
+}
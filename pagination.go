@@ -0,0 +1,194 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// PageOptions specifies the cursor-based pagination of list endpoints.
+type PageOptions struct {
+	// Limit is the maximum number of items per page, OneLogin caps it at 50.
+	Limit int `url:"limit,omitempty"`
+
+	// AfterCursor starts the iteration at the page following the cursor.
+	AfterCursor string `url:"after_cursor,omitempty"`
+
+	// BeforeCursor starts the iteration at the page preceding the cursor,
+	// pages are then walked backwards.
+	BeforeCursor string `url:"before_cursor,omitempty"`
+}
+
+// v2PageOptions are the PageOptions of the v2 API, which takes the cursor of
+// either direction as a single parameter.
+type v2PageOptions struct {
+	Limit  int    `url:"limit,omitempty"`
+	Cursor string `url:"cursor,omitempty"`
+}
+
+func (o *PageOptions) v2(backward bool) *v2PageOptions {
+	opt := &v2PageOptions{Limit: o.Limit, Cursor: o.AfterCursor}
+	if backward {
+		opt.Cursor = o.BeforeCursor
+	}
+
+	return opt
+}
+
+// An Iterator walks the pages of a list endpoint, one request per page.
+// Pages are streamed so that only the current page is held in memory:
+//
+//	it := c.User.Pages(&onelogin.PageOptions{Limit: 50})
+//	for {
+//		var users []*onelogin.User
+//		if !it.Next(ctx, &users) {
+//			break
+//		}
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+//
+// To resume an iteration later (e.g., after a crash), save the options
+// returned by Cursor and pass them to a new Iterator.
+type Iterator struct {
+	client *Client
+	url    string // list endpoint, with its filters
+	opt    PageOptions
+
+	backward bool
+	done     bool
+	err      error
+	resp     *Response
+}
+
+// newIterator returns an Iterator over the pages of the list endpoint u,
+// which may already hold query parameters (e.g., filters).
+func newIterator(c *Client, u string, opt *PageOptions) *Iterator {
+	it := &Iterator{client: c, url: u}
+	if opt != nil {
+		it.opt = *opt
+		it.backward = opt.BeforeCursor != ""
+	}
+
+	return it
+}
+
+// pageOptions returns o, it is promoted to the list options that embed
+// PageOptions.
+func (o *PageOptions) pageOptions() *PageOptions {
+	return o
+}
+
+// listOptions are the options of a list endpoint: filters encoded as URL
+// query parameters, and an embedded PageOptions tagged `url:"-"` that is
+// handled by the Iterator.
+type listOptions interface {
+	pageOptions() *PageOptions
+}
+
+// newListIterator returns an Iterator over the pages of the list endpoint u,
+// filtered by opt, which may be nil.
+func newListIterator(c *Client, u string, opt listOptions) *Iterator {
+	if v := reflect.ValueOf(opt); !v.IsValid() || v.IsNil() {
+		return newIterator(c, u, nil)
+	}
+
+	u, err := addOptions(u, opt)
+
+	it := newIterator(c, u, opt.pageOptions())
+	it.err = err
+
+	return it
+}
+
+// Next fetches the next page and decodes its items into v, which must be a
+// pointer to a slice. It returns false once all the pages have been walked,
+// or if an error occurred, see Err.
+func (it *Iterator) Next(ctx context.Context, v interface{}) bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	var opt interface{} = &it.opt
+	if isV2(it.url) {
+		opt = it.opt.v2(it.backward)
+	}
+
+	u, err := addOptions(it.url, opt)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	req, err := it.client.NewRequest("GET", u, nil)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	if err := it.client.AddAuthorization(ctx, req); err != nil {
+		it.err = err
+		return false
+	}
+
+	resp, err := it.client.Do(ctx, req, v)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.resp = resp
+
+	cursor := resp.PaginationAfterCursor
+	if it.backward {
+		cursor = resp.PaginationBeforeCursor
+	}
+
+	switch {
+	case cursor == nil || *cursor == "":
+		it.done = true
+	case it.backward:
+		it.opt.BeforeCursor = *cursor
+	default:
+		it.opt.AfterCursor = *cursor
+	}
+
+	return true
+}
+
+// All fetches the remaining pages and appends their items to v, which must be
+// a pointer to a slice.
+func (it *Iterator) All(ctx context.Context, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("iterator: v must be a pointer to a slice")
+	}
+	items := rv.Elem()
+
+	for {
+		page := reflect.New(items.Type())
+		if !it.Next(ctx, page.Interface()) {
+			break
+		}
+		items.Set(reflect.AppendSlice(items, page.Elem()))
+	}
+
+	return it.Err()
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Response returns the response of the last page fetched.
+func (it *Iterator) Response() *Response {
+	return it.resp
+}
+
+// Cursor returns the options that resume the iteration at the next page. ok
+// is false once all the pages have been walked.
+func (it *Iterator) Cursor() (opt PageOptions, ok bool) {
+	return it.opt, !it.done
+}
@@ -0,0 +1,122 @@
+package onelogin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// handlePages serves 3 pages of roles, linked by cursors "1" to "3".
+func handlePages(t *testing.T, mux *http.ServeMux) {
+	mux.HandleFunc("/api/1/roles", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("limit"); got != "1" {
+			t.Errorf("limit got: %v, want: 1", got)
+		}
+
+		page := 1
+		if c := r.URL.Query().Get("after_cursor"); c != "" {
+			fmt.Sscan(c, &page)
+		}
+		if c := r.URL.Query().Get("before_cursor"); c != "" {
+			fmt.Sscan(c, &page)
+		}
+
+		after, before := "null", "null"
+		if page < 3 {
+			after = fmt.Sprintf(`"%d"`, page+1)
+		}
+		if page > 1 {
+			before = fmt.Sprintf(`"%d"`, page-1)
+		}
+		fmt.Fprintf(w, `{"status":{"error":false,"code":200},
+			"pagination":{"before_cursor":%s,"after_cursor":%s},
+			"data":[{"id":%d,"name":"role %d"}]}`, before, after, page, page)
+	})
+}
+
+func iterate(it *Iterator) ([]int64, error) {
+	var ids []int64
+	for {
+		var roles []*Role
+		if !it.Next(context.Background(), &roles) {
+			break
+		}
+		for _, r := range roles {
+			ids = append(ids, r.ID)
+		}
+	}
+
+	return ids, it.Err()
+}
+
+func TestIterator(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  PageOptions
+		want string
+	}{
+		{"forward", PageOptions{Limit: 1}, "[1 2 3]"},
+		{"resume after cursor", PageOptions{Limit: 1, AfterCursor: "2"}, "[2 3]"},
+		{"backward", PageOptions{Limit: 1, BeforeCursor: "3"}, "[3 2 1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mux, teardown := setup()
+			defer teardown()
+			handlePages(t, mux)
+
+			ids, err := iterate(c.Role.Pages(&tt.opt))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := fmt.Sprint(ids); got != tt.want {
+				t.Errorf("got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIterator_Cursor(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+	handlePages(t, mux)
+
+	it := c.Role.Pages(&PageOptions{Limit: 1})
+	var roles []*Role
+	if !it.Next(context.Background(), &roles) {
+		t.Fatalf("unexpected error: %v", it.Err())
+	}
+
+	// resume with a new iterator, as if the process crashed
+	opt, ok := it.Cursor()
+	if !ok {
+		t.Fatal("the iteration is not complete")
+	}
+	ids, err := iterate(c.Role.Pages(&opt))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fmt.Sprint(ids); got != "[2 3]" {
+		t.Errorf("got: %v, want: %v", got, "[2 3]")
+	}
+
+	if _, ok := it.Cursor(); !ok {
+		t.Error("the original iteration should not be complete")
+	}
+}
+
+func TestIterator_All(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+	handlePages(t, mux)
+
+	var roles []*Role
+	if err := c.Role.Pages(&PageOptions{Limit: 1}).All(context.Background(), &roles); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roles) != 3 || roles[2].Name != "role 3" {
+		t.Errorf("got: %v roles, want: 3", len(roles))
+	}
+}
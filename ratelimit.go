@@ -0,0 +1,165 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate represents the rate limit of the API credential in use.
+// https://developers.onelogin.com/api-docs/1/getting-started/rate-limits
+type Rate struct {
+	// The number of requests allowed per rate limit window.
+	Limit int
+	// The number of requests remaining in the current window.
+	Remaining int
+	// The time at which the current window resets.
+	Reset time.Time
+}
+
+// parseRate reads the X-RateLimit-* headers of a response.
+func parseRate(h http.Header) Rate {
+	var rate Rate
+	rate.Limit, _ = strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	rate.Remaining, _ = strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if d, ok := rateLimitReset(h); ok {
+		rate.Reset = now().Add(d)
+	}
+
+	return rate
+}
+
+// Rate returns the rate limit reported by the most recent API response. The
+// zero value is returned if no response carried rate limit headers yet.
+func (c *Client) Rate() Rate {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	return c.rate
+}
+
+// observeRate records the rate limit reported in the response headers and
+// forwards it to the Limiter.
+func (c *Client) observeRate(h http.Header) {
+	if h.Get("X-RateLimit-Limit") == "" {
+		return
+	}
+
+	rate := parseRate(h)
+	c.rateMu.Lock()
+	c.rate = rate
+	c.rateMu.Unlock()
+
+	if o, ok := c.Limiter.(rateObserver); ok {
+		o.observe(rate)
+	}
+}
+
+// A Limiter paces the requests sent by a Client. The limiter of a Client is
+// shared by all of its services.
+type Limiter interface {
+	// Wait blocks until a request may be sent, or until ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// rateObserver is implemented by limiters that adjust to the rate limit
+// reported by OneLogin.
+type rateObserver interface {
+	observe(rate Rate)
+}
+
+// RateLimiter is a token bucket Limiter. Besides its own pace, it follows the
+// X-RateLimit-* headers returned by OneLogin: it never hands out more tokens
+// than the API reports as remaining, and blocks until the window resets once
+// the quota is exhausted. It is safe for concurrent use.
+type RateLimiter struct {
+	mu sync.Mutex
+
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// the API quota is exhausted until then
+	blockedUntil time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that allows limit requests per
+// interval, with bursts of up to burst requests. For instance, OneLogin's
+// default quota is NewRateLimiter(5000, time.Hour, 100). limit and interval
+// must be positive, a burst below 1 is raised to 1.
+func NewRateLimiter(limit int, interval time.Duration, burst int) (*RateLimiter, error) {
+	if limit <= 0 {
+		return nil, errors.New("rate limit must be positive")
+	}
+	if interval <= 0 {
+		return nil, errors.New("rate limit interval must be positive")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   float64(limit) / interval.Seconds(),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now(),
+	}, nil
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	t := now()
+	l.advance(t)
+	l.tokens--
+
+	var d time.Duration
+	if l.tokens < 0 && l.rate > 0 {
+		d = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	if b := l.blockedUntil.Sub(t); b > d {
+		d = b
+	}
+	l.mu.Unlock()
+
+	if d <= 0 {
+		return nil
+	}
+
+	if err := sleep(ctx, d); err != nil {
+		// give back the token, it won't be used
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// advance refills the bucket with the tokens accumulated since the last call.
+func (l *RateLimiter) advance(t time.Time) {
+	if elapsed := t.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = t
+}
+
+func (l *RateLimiter) observe(rate Rate) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r := float64(rate.Remaining); r < l.tokens {
+		l.tokens = r
+	}
+	if rate.Remaining == 0 && rate.Reset.After(l.blockedUntil) {
+		l.blockedUntil = rate.Reset
+	}
+}
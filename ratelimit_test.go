@@ -0,0 +1,98 @@
+package onelogin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	now = time.Now
+
+	l, err := NewRateLimiter(20, time.Second, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// the burst is free, the 2 other tokens are refilled every 50ms
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("got: %v, want at least 100ms", elapsed)
+	}
+}
+
+func TestNewRateLimiter_invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		interval time.Duration
+	}{
+		{"zero limit", 0, time.Second},
+		{"negative limit", -1, time.Second},
+		{"zero interval", 20, 0},
+		{"negative interval", 20, -time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRateLimiter(tt.limit, tt.interval, 2); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRateLimiter_observe(t *testing.T) {
+	now = time.Now
+
+	l, err := NewRateLimiter(5000, time.Hour, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.observe(Rate{Limit: 5000, Remaining: 0, Reset: time.Now().Add(time.Hour)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClient_Rate(t *testing.T) {
+	now = time.Now
+
+	c, mux, teardown := setup()
+	defer teardown()
+	l, err := NewRateLimiter(5000, time.Hour, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Limiter = l
+
+	mux.HandleFunc("/api/1/test", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Reset", "3600")
+		fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+	})
+
+	req, _ := c.NewRequest("GET", "/api/1/test", nil)
+	resp, err := c.Do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Rate.Remaining != 4999 {
+		t.Errorf("got: %v, want: %v", resp.Rate.Remaining, 4999)
+	}
+	if got := c.Rate(); got.Limit != 5000 || got.Remaining != 4999 {
+		t.Errorf("got: %+v, want: %+v", got, resp.Rate)
+	}
+}
@@ -0,0 +1,280 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how Client.Do retries requests that failed with a
+// transient error. A nil policy disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, it doubles on every
+	// following attempt up to MaxDelay. A zero MaxDelay means no cap.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Jitter is the fraction (between 0 and 1) of each backoff delay that is
+	// randomized, so that concurrent clients don't retry in lockstep.
+	Jitter float64
+
+	// RetryableStatusCodes lists the HTTP status codes that are retried.
+	RetryableStatusCodes []int
+
+	// RetryableError reports whether a transport error is retried. When nil,
+	// timeouts, connection resets and refused connections are retried.
+	RetryableError func(error) bool
+
+	// RetryNonIdempotent allows POST and PATCH requests to be retried on any
+	// retryable status code or error. By default they are only retried when
+	// OneLogin rejected them with 429 Too Many Requests, or when they carry
+	// an Idempotency-Key header.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the policy used by clients created with New.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// canRetry reports whether another attempt of req is allowed after attempt.
+func (p *RetryPolicy) canRetry(req *http.Request, attempt int) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	// the body must be replayable
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryStatus reports whether the response status code is retried for req.
+func (p *RetryPolicy) retryStatus(req *http.Request, code int) bool {
+	if code != http.StatusTooManyRequests && !p.idempotent(req) {
+		return false
+	}
+
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryError reports whether the transport error is retried for req.
+func (p *RetryPolicy) retryError(req *http.Request, err error) bool {
+	// nothing reached the server if the connection was never established
+	var opErr *net.OpError
+	dialed := !(errors.As(err, &opErr) && opErr.Op == "dial")
+	if dialed && !p.idempotent(req) {
+		return false
+	}
+
+	if p.RetryableError != nil {
+		return p.RetryableError(err)
+	}
+
+	return isTransientError(err)
+}
+
+func (p *RetryPolicy) idempotent(req *http.Request) bool {
+	if p.RetryNonIdempotent {
+		return true
+	}
+
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+
+	_, ok := req.Header["Idempotency-Key"]
+	return ok
+}
+
+// backoff returns the exponential delay before the retry following attempt.
+// A zero MaxDelay doesn't cap the delay.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < math.MaxInt64/2 && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		j := time.Duration(p.Jitter * float64(d))
+		if j > 0 {
+			d = d - j + time.Duration(rand.Int63n(int64(2*j)))
+		}
+	}
+
+	return d
+}
+
+// delay returns how long to wait before retrying the request that produced
+// resp. The server hint (Retry-After or X-RateLimit-Reset) takes precedence
+// over the backoff. ok is false if the server asks to wait longer than
+// MaxDelay.
+func (p *RetryPolicy) delay(resp *http.Response, attempt int) (d time.Duration, ok bool) {
+	d, hinted := retryAfter(resp.Header)
+	if !hinted && resp.StatusCode == http.StatusTooManyRequests {
+		d, hinted = rateLimitReset(resp.Header)
+	}
+	if !hinted {
+		return p.backoff(attempt), true
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return 0, false
+	}
+
+	return d, true
+}
+
+// retryAfter parses the Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			s = 0
+		}
+		return time.Duration(s) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	d := t.Sub(now())
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
+
+// rateLimitReset parses OneLogin's X-RateLimit-Reset header, the number of
+// seconds until the rate limit window resets.
+func rateLimitReset(h http.Header) (time.Duration, bool) {
+	s, err := strconv.Atoi(h.Get("X-RateLimit-Reset"))
+	if err != nil || s < 0 {
+		return 0, false
+	}
+
+	return time.Duration(s) * time.Second, true
+}
+
+// isTransientError reports whether err is a network error worth retrying.
+func isTransientError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// send performs req, paced by the client Limiter and retried according to
+// the client RetryPolicy. The returned response body is left open for the
+// caller.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	p := c.RetryPolicy
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		var d time.Duration
+		resp, err := c.client.Do(req)
+		if err != nil {
+			// If we got an error, and the context has been canceled,
+			// the context's error is probably more useful.
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			default:
+			}
+
+			if !p.canRetry(req, attempt) || !p.retryError(req, err) {
+				return nil, err
+			}
+			d = p.backoff(attempt)
+		} else {
+			c.observeRate(resp.Header)
+			if !p.canRetry(req, attempt) || !p.retryStatus(req, resp.StatusCode) {
+				return resp, nil
+			}
+
+			var ok bool
+			if d, ok = p.delay(resp, attempt); !ok {
+				return resp, nil
+			}
+
+			// discard the failed attempt, the connection can be reused
+			_, _ = io.CopyN(ioutil.Discard, resp.Body, 512)
+			_ = resp.Body.Close()
+		}
+
+		if err := sleep(ctx, d); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep pauses for d or until ctx is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -1,58 +1,175 @@
 package onelogin
 
 import (
+	"context"
+	"errors"
 	"fmt"
-
-	"golang.org/x/net/context"
+	"sync"
+	"time"
 )
 
 // RoleService deals with OneLogin roles.
 type RoleService struct {
 	*service
+
+	// cache of the role IDs by name, see RoleID
+	mu         sync.Mutex
+	cacheTTL   time.Duration // zero if the cache is disabled
+	roleIDs    map[string]int64
+	loadedAt   time.Time
+	generation int // incremented by InvalidateCache
 }
 
-// Role contains the ID (immutable) and name (mutable) of a role.
+// Role contains the ID (immutable) and name (mutable) of a role. Apps, Users
+// and Admins hold IDs and are only set by the v2 API.
 type Role struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID     int64   `json:"id"`
+	Name   string  `json:"name"`
+	Apps   []int64 `json:"apps,omitempty"`
+	Users  []int64 `json:"users,omitempty"`
+	Admins []int64 `json:"admins,omitempty"`
+}
+
+// A RoleUser is a user assigned to a role, or one of its admins.
+type RoleUser struct {
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Username string    `json:"username"`
+	AddedAt  Timestamp `json:"added_at"`
+}
+
+// A RoleApp is an app attached to a role.
+type RoleApp struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	IconURL string `json:"icon_url"`
 }
 
 // GetRoles returns all the OneLogin Roles.
 func (s *RoleService) GetRoles(ctx context.Context) ([]*Role, error) {
-	u := "/api/1/roles"
+	var roles []*Role
+	if err := s.Pages(nil).All(ctx, &roles); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// Pages returns an Iterator over the pages of OneLogin roles, its items are
+// *Role.
+func (s *RoleService) Pages(opt *PageOptions) *Iterator {
+	return newIterator(s.client, "/api/1/roles", opt)
+}
 
+// RoleListOptions specifies the filters of ListRoles. Name accepts a "*"
+// wildcard (e.g., "Eng*").
+//
+// https://developers.onelogin.com/api-docs/2/roles/list-roles
+type RoleListOptions struct {
+	ID    int64  `url:"id,omitempty"`
+	Name  string `url:"name,omitempty"`
+	AppID int64  `url:"app_id,omitempty"`
+
+	PageOptions `url:"-"`
+}
+
+// ListRoles returns the OneLogin roles matching opt.
+func (s *RoleService) ListRoles(ctx context.Context, opt *RoleListOptions) ([]*Role, error) {
 	var roles []*Role
-	var afterCursor string
+	if err := s.ListRolesPages(opt).All(ctx, &roles); err != nil {
+		return nil, err
+	}
 
-	for {
-		uu, err := addOptions(u, &urlQuery{AfterCursor: afterCursor})
-		if err != nil {
-			return nil, err
+	return roles, nil
+}
+
+// ListRolesPages returns an Iterator over the pages of the OneLogin roles
+// matching opt, its items are *Role.
+func (s *RoleService) ListRolesPages(opt *RoleListOptions) *Iterator {
+	return newListIterator(s.client, "/api/2/roles", opt)
+}
+
+// GetRoleByName returns the OneLogin role named name, ErrNotFound is wrapped
+// if there is none.
+func (s *RoleService) GetRoleByName(ctx context.Context, name string) (*Role, error) {
+	roles, err := s.ListRoles(ctx, &RoleListOptions{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	// the filter matches names loosely, e.g., regardless of the case
+	for _, role := range roles {
+		if role.Name == name {
+			return role, nil
 		}
+	}
 
-		req, err := s.client.NewRequest("GET", uu, nil)
+	return nil, roleNotFound(name)
+}
+
+// RoleID returns the ID of the role named name, ErrNotFound is wrapped if
+// there is none. If the cache is enabled (see WithRoleCache), the IDs of all
+// the roles are loaded at once and reused until the cache expires.
+func (s *RoleService) RoleID(ctx context.Context, name string) (int64, error) {
+	if s.cacheTTL <= 0 {
+		role, err := s.GetRoleByName(ctx, name)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
+		return role.ID, nil
+	}
 
-		if err := s.client.AddAuthorization(ctx, req); err != nil {
-			return nil, err
-		}
+	s.mu.Lock()
+	roleIDs := s.roleIDs
+	if roleIDs != nil && now().Sub(s.loadedAt) > s.cacheTTL {
+		roleIDs = nil
+	}
+	generation := s.generation
+	s.mu.Unlock()
 
-		var rs []*Role
-		resp, err := s.client.Do(ctx, req, &rs)
+	if roleIDs == nil {
+		// the roles are loaded without holding the lock, so that callers
+		// don't wait on each other regardless of their own ctx
+		roles, err := s.ListRoles(ctx, nil)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
-		roles = append(roles, rs...)
-		if resp.PaginationAfterCursor == nil {
-			break
+
+		roleIDs = make(map[string]int64, len(roles))
+		for _, role := range roles {
+			roleIDs[role.Name] = role.ID
 		}
 
-		afterCursor = *resp.PaginationAfterCursor
+		s.mu.Lock()
+		// roles changed during the load may be missing from it
+		if s.generation == generation {
+			s.roleIDs = roleIDs
+			s.loadedAt = now()
+		}
+		s.mu.Unlock()
 	}
 
-	return roles, nil
+	id, ok := roleIDs[name]
+	if !ok {
+		return 0, roleNotFound(name)
+	}
+
+	return id, nil
+}
+
+// InvalidateCache empties the cache of the role IDs, they are loaded again by
+// the next call to RoleID. Roles created, updated or deleted by the client
+// invalidate the cache.
+func (s *RoleService) InvalidateCache() {
+	s.mu.Lock()
+	s.roleIDs = nil
+	s.generation++
+	s.mu.Unlock()
+}
+
+func roleNotFound(name string) error {
+	return &wrappedError{msg: fmt.Sprintf("role not found: %q", name), err: ErrNotFound}
 }
 
 // GetRole returns a OneLogin role specified by its ID.
@@ -74,5 +191,184 @@ func (s *RoleService) GetRole(ctx context.Context, id int64) (*Role, error) {
 		return nil, err
 	}
 
+	if len(roles) != 1 {
+		return nil, errors.New("unexpected role response")
+	}
+
 	return roles[0], nil
 }
+
+type createRoleParams struct {
+	Name   string  `json:"name"`
+	Apps   []int64 `json:"apps,omitempty"`
+	Users  []int64 `json:"users,omitempty"`
+	Admins []int64 `json:"admins,omitempty"`
+}
+
+type roleIDResponse struct {
+	ID int64 `json:"id"`
+}
+
+// CreateRole creates a role with its apps, users and admins, and returns it
+// with its ID.
+//
+// https://developers.onelogin.com/api-docs/2/roles/create-role
+func (s *RoleService) CreateRole(ctx context.Context, role *Role) (*Role, error) {
+	u := "/api/2/roles"
+
+	if role == nil {
+		return nil, errors.New("no role to create")
+	}
+	if role.Name == "" {
+		return nil, errors.New("required field is missing: 'name'")
+	}
+
+	b := createRoleParams{
+		Name:   role.Name,
+		Apps:   role.Apps,
+		Users:  role.Users,
+		Admins: role.Admins,
+	}
+	var r roleIDResponse
+	if err := s.send(ctx, "POST", u, b, &r); err != nil {
+		return nil, err
+	}
+	s.InvalidateCache()
+
+	created := *role
+	created.ID = r.ID
+	return &created, nil
+}
+
+// UpdateRole renames a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/update-role
+func (s *RoleService) UpdateRole(ctx context.Context, id int64, name string) error {
+	u := fmt.Sprintf("/api/2/roles/%v", id)
+
+	if name == "" {
+		return errors.New("required field is missing: 'name'")
+	}
+
+	b := map[string]string{
+		"name": name,
+	}
+	if err := s.send(ctx, "PUT", u, b, nil); err != nil {
+		return err
+	}
+	s.InvalidateCache()
+
+	return nil
+}
+
+// DeleteRole deletes a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/delete-role
+func (s *RoleService) DeleteRole(ctx context.Context, id int64) error {
+	u := fmt.Sprintf("/api/2/roles/%v", id)
+
+	if err := s.send(ctx, "DELETE", u, nil, nil); err != nil {
+		return err
+	}
+	s.InvalidateCache()
+
+	return nil
+}
+
+// GetRoleUsers returns the users assigned to a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/get-role-users
+func (s *RoleService) GetRoleUsers(ctx context.Context, id int64) ([]*RoleUser, error) {
+	var users []*RoleUser
+	if err := s.RoleUsersPages(id, nil).All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// RoleUsersPages returns an Iterator over the pages of the users assigned to
+// a role, its items are *RoleUser.
+func (s *RoleService) RoleUsersPages(id int64, opt *PageOptions) *Iterator {
+	return newIterator(s.client, fmt.Sprintf("/api/2/roles/%v/users", id), opt)
+}
+
+// AddRoleUsers assigns users to a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/add-role-users
+func (s *RoleService) AddRoleUsers(ctx context.Context, id int64, userIDs []int64) error {
+	u := fmt.Sprintf("/api/2/roles/%v/users", id)
+
+	return s.send(ctx, "POST", u, userIDs, nil)
+}
+
+// RemoveRoleUsers removes users from a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/remove-role-users
+func (s *RoleService) RemoveRoleUsers(ctx context.Context, id int64, userIDs []int64) error {
+	u := fmt.Sprintf("/api/2/roles/%v/users", id)
+
+	return s.send(ctx, "DELETE", u, userIDs, nil)
+}
+
+// GetRoleAdmins returns the admins of a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/get-role-admins
+func (s *RoleService) GetRoleAdmins(ctx context.Context, id int64) ([]*RoleUser, error) {
+	var admins []*RoleUser
+	if err := s.RoleAdminsPages(id, nil).All(ctx, &admins); err != nil {
+		return nil, err
+	}
+
+	return admins, nil
+}
+
+// RoleAdminsPages returns an Iterator over the pages of the admins of a role,
+// its items are *RoleUser.
+func (s *RoleService) RoleAdminsPages(id int64, opt *PageOptions) *Iterator {
+	return newIterator(s.client, fmt.Sprintf("/api/2/roles/%v/admins", id), opt)
+}
+
+// AddRoleAdmins makes users admins of a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/add-role-admins
+func (s *RoleService) AddRoleAdmins(ctx context.Context, id int64, userIDs []int64) error {
+	u := fmt.Sprintf("/api/2/roles/%v/admins", id)
+
+	return s.send(ctx, "POST", u, userIDs, nil)
+}
+
+// RemoveRoleAdmins removes admins of a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/remove-role-admins
+func (s *RoleService) RemoveRoleAdmins(ctx context.Context, id int64, userIDs []int64) error {
+	u := fmt.Sprintf("/api/2/roles/%v/admins", id)
+
+	return s.send(ctx, "DELETE", u, userIDs, nil)
+}
+
+// GetRoleApps returns the apps attached to a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/get-role-apps
+func (s *RoleService) GetRoleApps(ctx context.Context, id int64) ([]*RoleApp, error) {
+	var apps []*RoleApp
+	it := newIterator(s.client, fmt.Sprintf("/api/2/roles/%v/apps", id), nil)
+	if err := it.All(ctx, &apps); err != nil {
+		return nil, err
+	}
+
+	return apps, nil
+}
+
+// SetRoleApps replaces the apps attached to a role.
+//
+// https://developers.onelogin.com/api-docs/2/roles/set-role-apps
+func (s *RoleService) SetRoleApps(ctx context.Context, id int64, appIDs []int64) error {
+	u := fmt.Sprintf("/api/2/roles/%v/apps", id)
+
+	if appIDs == nil {
+		// detach all the apps, rather than sending null
+		appIDs = []int64{}
+	}
+	return s.send(ctx, "PUT", u, appIDs, nil)
+}
@@ -0,0 +1,287 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoleService_CreateRole(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/2/roles", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("method got: %v, want: POST", r.Method)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if got, want := strings.TrimSpace(string(b)), `{"name":"Engineering","apps":[7],"users":[1,2]}`; got != want {
+			t.Errorf("body got: %v, want: %v", got, want)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":42}`)
+	})
+
+	if _, err := c.Role.CreateRole(context.Background(), nil); err == nil {
+		t.Error("expected error for a nil role, got nil")
+	}
+
+	role, err := c.Role.CreateRole(context.Background(), &Role{Name: "Engineering", Apps: []int64{7}, Users: []int64{1, 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if role.ID != 42 || role.Name != "Engineering" {
+		t.Errorf("got: %+v", role)
+	}
+}
+
+func TestRoleService_GetRoleUsers(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/2/roles/42/users", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Header().Set("After-Cursor", "next")
+			fmt.Fprint(w, `[{"id":1,"name":"Jane Doe","email":"jane@example.com","added_at":"2019-03-12T17:13:54.123Z"}]`)
+		case "next":
+			fmt.Fprint(w, `[{"id":2,"name":"John Doe","email":"john@example.com"}]`)
+		default:
+			t.Errorf("unexpected cursor: %v", r.URL.RawQuery)
+		}
+	})
+
+	users, err := c.Role.GetRoleUsers(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 || users[0].ID != 1 || users[1].ID != 2 {
+		t.Fatalf("got: %+v", users)
+	}
+	if users[0].AddedAt.IsZero() || !users[1].AddedAt.IsZero() {
+		t.Errorf("added at got: %v, %v", users[0].AddedAt, users[1].AddedAt)
+	}
+}
+
+func TestRoleService_DeleteRole_notFound(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/2/roles/42", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"statusCode":404,"name":"NotFoundError","message":"Role not found"}`)
+	})
+
+	err := c.Role.DeleteRole(context.Background(), 42)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got: %v, want: %v", err, ErrNotFound)
+	}
+
+	var errResp *ErrorResponse
+	if errors.As(err, &errResp) && (errResp.Type != "NotFoundError" || errResp.Message != "Role not found") {
+		t.Errorf("got: %+v", errResp)
+	}
+}
+
+func TestRoleService_GetRoleByName(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/2/roles", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") == "" {
+			t.Error("missing name filter")
+		}
+		fmt.Fprint(w, `[{"id":1,"name":"admins"},{"id":2,"name":"Admins"}]`)
+	})
+
+	role, err := c.Role.GetRoleByName(context.Background(), "Admins")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if role.ID != 2 {
+		t.Errorf("got: %+v", role)
+	}
+
+	if _, err := c.Role.GetRoleByName(context.Background(), "ADMINS"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got: %v, want: %v", err, ErrNotFound)
+	}
+}
+
+func TestRoleService_RoleID_cache(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+	c.Role.cacheTTL = time.Hour
+
+	var loads int
+	mux.HandleFunc("/api/2/roles", func(w http.ResponseWriter, r *http.Request) {
+		loads++
+		fmt.Fprint(w, `[{"id":1,"name":"Admins"},{"id":2,"name":"Engineering"}]`)
+	})
+	mux.HandleFunc("/api/2/roles/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":2}`)
+	})
+
+	for _, name := range []string{"Admins", "Engineering"} {
+		if _, err := c.Role.RoleID(context.Background(), name); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := c.Role.RoleID(context.Background(), "Sales"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got: %v, want: %v", err, ErrNotFound)
+	}
+	if loads != 1 {
+		t.Errorf("loads got: %v, want: 1", loads)
+	}
+
+	if err := c.Role.UpdateRole(context.Background(), 2, "Eng"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.Role.RoleID(context.Background(), "Admins"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loads != 2 {
+		t.Errorf("loads after update got: %v, want: 2", loads)
+	}
+}
+
+func TestRoleService_authorization(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/2/roles/42/apps", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Bearer token"; got != want {
+			t.Errorf("Authorization got: %v, want: %v", got, want)
+		}
+		fmt.Fprint(w, `[{"id":7,"name":"Slack","icon_url":"https://example.com/slack.png"}]`)
+	})
+
+	apps, err := c.Role.GetRoleApps(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(apps) != 1 || apps[0].ID != 7 {
+		t.Errorf("got: %+v", apps)
+	}
+}
+
+func TestRoleService_unauthorized(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	// the token is rejected once, the request is retried with a new token
+	var calls int
+	mux.HandleFunc("/api/2/roles/42", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"statusCode":401,"name":"Unauthorized","message":"Authentication Failure"}`)
+			return
+		}
+		if got, want := r.Header.Get("Authorization"), "Bearer token"; got != want {
+			t.Errorf("Authorization got: %v, want: %v", got, want)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if err := c.Role.DeleteRole(context.Background(), 42); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls got: %v, want: 2", calls)
+	}
+}
+
+func TestRoleService_RoleID_concurrent(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+	c.Role.cacheTTL = time.Hour
+
+	release := make(chan struct{})
+	mux.HandleFunc("/api/2/roles", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Fprint(w, `[{"id":1,"name":"Admins"}]`)
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Role.RoleID(context.Background(), "Admins")
+		done <- err
+	}()
+
+	// a caller doesn't wait for the load of another caller beyond its own ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Role.RoleID(ctx, "Admins"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRoleService_GetRole_emptyResponse(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/roles/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[]}`)
+	})
+
+	if _, err := c.Role.GetRole(context.Background(), 1); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestRoleService_membership(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(c *Client) error
+		wantMethod string
+		wantPath   string
+		wantBody   string
+	}{
+		{"AddRoleUsers", func(c *Client) error { return c.Role.AddRoleUsers(context.Background(), 42, []int64{1, 2}) }, "POST", "/api/2/roles/42/users", `[1,2]`},
+		{"RemoveRoleUsers", func(c *Client) error { return c.Role.RemoveRoleUsers(context.Background(), 42, []int64{1, 2}) }, "DELETE", "/api/2/roles/42/users", `[1,2]`},
+		{"AddRoleAdmins", func(c *Client) error { return c.Role.AddRoleAdmins(context.Background(), 42, []int64{3}) }, "POST", "/api/2/roles/42/admins", `[3]`},
+		{"RemoveRoleAdmins", func(c *Client) error { return c.Role.RemoveRoleAdmins(context.Background(), 42, []int64{3, 4}) }, "DELETE", "/api/2/roles/42/admins", `[3,4]`},
+		{"SetRoleApps", func(c *Client) error { return c.Role.SetRoleApps(context.Background(), 42, []int64{7}) }, "PUT", "/api/2/roles/42/apps", `[7]`},
+		{"SetRoleApps no apps", func(c *Client) error { return c.Role.SetRoleApps(context.Background(), 42, nil) }, "PUT", "/api/2/roles/42/apps", `[]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mux, teardown := setup()
+			defer teardown()
+
+			var called bool
+			mux.HandleFunc(tt.wantPath, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				if r.Method != tt.wantMethod {
+					t.Errorf("method got: %v, want: %v", r.Method, tt.wantMethod)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer token" {
+					t.Errorf("Authorization got: %v, want: Bearer token", got)
+				}
+				b, _ := ioutil.ReadAll(r.Body)
+				if got := strings.TrimSpace(string(b)); got != tt.wantBody {
+					t.Errorf("body got: %v, want: %v", got, tt.wantBody)
+				}
+				fmt.Fprint(w, `[{"id":1}]`)
+			})
+
+			if err := tt.call(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !called {
+				t.Errorf("%v %v wasn't called", tt.wantMethod, tt.wantPath)
+			}
+		})
+	}
+}
@@ -92,7 +92,7 @@ func (s *SAMLService) GenerateSAMLAssertion(ctx context.Context, emailOrUsername
 	}
 
 	if m.Status.Error {
-		return nil, fmt.Errorf("unexpected error generating SAML assertion: %s", m.Status.Message)
+		return nil, statusError(resp.Response, &m, nil)
 	}
 	assertion := &SAMLAssertion{
 		Status:  m.Status.Type,
@@ -0,0 +1,69 @@
+package onelogin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// timestampLayouts are the formats of the timestamps returned by OneLogin.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+}
+
+// A Timestamp is a time returned by OneLogin. It decodes any of the formats
+// used by the API, null and empty strings decode to the zero time.
+type Timestamp struct {
+	time.Time
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("timestamp %s: %v", data, err)
+	}
+
+	tm, err := parseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	t.Time = tm
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, the zero time is
+// encoded as null.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(t.Format(time.RFC3339Nano))
+}
+
+// parseTimestamp parses a timestamp returned by OneLogin, an empty string is
+// the zero time.
+func parseTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown timestamp format: %q", s)
+}
@@ -0,0 +1,60 @@
+package onelogin
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want time.Time
+	}{
+		{"null", `null`, time.Time{}},
+		{"empty", `""`, time.Time{}},
+		{"rfc3339", `"2019-03-12T17:13:54Z"`, time.Date(2019, 3, 12, 17, 13, 54, 0, time.UTC)},
+		{"milliseconds", `"2019-03-12T17:13:54.123Z"`, time.Date(2019, 3, 12, 17, 13, 54, 123000000, time.UTC)},
+		{"no zone", `"2019-03-12T17:13:54.123"`, time.Date(2019, 3, 12, 17, 13, 54, 123000000, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ts Timestamp
+			if err := json.Unmarshal([]byte(tt.data), &ts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ts.Equal(tt.want) {
+				t.Errorf("got: %v, want: %v", ts.Time, tt.want)
+			}
+		})
+	}
+
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`"yesterday"`), &ts); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestUser_timestamps(t *testing.T) {
+	var u User
+	if err := json.Unmarshal([]byte(`{"created_at":"2019-03-12T17:13:54.123Z","locked_until":null,"last_login":"","updated_at":"yesterday"}`), &u); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := u.CreatedTime()
+	if want := time.Date(2019, 3, 12, 17, 13, 54, 123000000, time.UTC); err != nil || !got.Equal(want) {
+		t.Errorf("CreatedTime got: %v, %v, want: %v", got, err, want)
+	}
+	if got, err := u.LockedUntilTime(); err != nil || !got.IsZero() {
+		t.Errorf("LockedUntilTime got: %v, %v, want the zero time", got, err)
+	}
+	if got, err := u.LastLoginTime(); err != nil || !got.IsZero() {
+		t.Errorf("LastLoginTime got: %v, %v, want the zero time", got, err)
+	}
+	// a format that can't be parsed isn't mistaken for a missing timestamp
+	if _, err := u.UpdatedTime(); err == nil {
+		t.Error("UpdatedTime: expected an error for an unknown format")
+	}
+}
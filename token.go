@@ -0,0 +1,451 @@
+package onelogin
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// A Token is an access token issued by OneLogin to an API credential.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	AccountID    int       `json:"account_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresIn    int64     `json:"expires_in"`
+}
+
+// A TokenSource supplies the tokens that authorize the requests of a client,
+// instead of the client issuing its own tokens. It must be safe for
+// concurrent use.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// A TokenStore persists the tokens issued to a client so that they can be
+// reused across processes until they expire. Tokens are stored by key, the
+// client ID of the API credential. It must be safe for concurrent use.
+type TokenStore interface {
+	// Load returns the token stored for key, or nil if there is none.
+	Load(ctx context.Context, key string) (*Token, error)
+	// Save stores the token for key, replacing any previous token.
+	Save(ctx context.Context, key string, t *Token) error
+	// Delete removes the token stored for key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+const (
+	// tokenTimeout bounds a token acquisition, it isn't bound to the
+	// context of any of the callers waiting for it.
+	tokenTimeout = time.Minute
+
+	// refreshRetryInterval is the delay before a failed background refresh
+	// is attempted again.
+	refreshRetryInterval = 10 * time.Second
+)
+
+// A tokenFlight is a token acquisition in flight, shared by all the callers
+// that need a token meanwhile.
+type tokenFlight struct {
+	done  chan struct{} // closed once the acquisition is over
+	token *oauthToken
+	err   error
+}
+
+// token returns a valid token of the client. The current token is returned
+// right away unless it is nearly expired, in which case the caller waits for
+// a new token.
+func (c *Client) token(ctx context.Context) (*oauthToken, error) {
+	c.tokenMu.RLock()
+	t := c.oauthToken
+	refresh := t != nil && t.isRefreshDue() && c.flight == nil && now().After(c.nextRefresh) && !c.closed
+	c.tokenMu.RUnlock()
+
+	if t != nil && !t.isNearExpired() {
+		if refresh {
+			c.startFlight()
+		}
+		return t, nil
+	}
+
+	// the client is in use again after Close
+	c.tokenMu.Lock()
+	c.closed = false
+	c.tokenMu.Unlock()
+
+	f := c.startFlight()
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startFlight starts the acquisition of a new token, unless one is already
+// in flight, and returns it.
+func (c *Client) startFlight() *tokenFlight {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.flight != nil {
+		return c.flight
+	}
+
+	f := &tokenFlight{done: make(chan struct{})}
+	c.flight = f
+	current := c.oauthToken
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tokenTimeout)
+		defer cancel()
+
+		f.token, f.err = c.acquireToken(ctx, current)
+
+		c.tokenMu.Lock()
+		if f.err == nil {
+			c.oauthToken = f.token
+		} else {
+			c.nextRefresh = now().Add(refreshRetryInterval)
+		}
+		c.flight = nil
+		c.tokenMu.Unlock()
+
+		close(f.done)
+	}()
+
+	return f
+}
+
+// acquireToken returns a token that replaces current, which may be nil. A
+// token saved in the TokenStore by another client is preferred, otherwise
+// current is refreshed, or a new token is issued if it can't be.
+//
+// The TokenStore is best-effort: if it can't be read, or the new token can't
+// be saved (e.g., on a read-only filesystem), the token is still used, only
+// from memory.
+func (c *Client) acquireToken(ctx context.Context, current *oauthToken) (*oauthToken, error) {
+	stored, _ := c.loadToken(ctx)
+
+	c.tokenMu.RLock()
+	if stored != nil && stored.AccessToken == c.rejected {
+		stored = nil
+	}
+	c.tokenMu.RUnlock()
+
+	if stored != nil && !stored.isNearExpired() && (current == nil || stored.AccessToken != current.AccessToken) {
+		return stored, nil
+	}
+	if current == nil {
+		current = stored
+	}
+
+	var (
+		t   *oauthToken
+		err error
+	)
+	if current != nil {
+		t, err = current.refresh(ctx)
+		if err != nil && !refreshRejected(err) {
+			return nil, err
+		}
+	}
+	if current == nil || err != nil {
+		// the refresh token was rejected, issue a new token
+		t, err = c.Oauth.getToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the token is usable even if it can't be shared through the TokenStore
+	_ = c.saveToken(ctx, t)
+
+	return t, nil
+}
+
+// refreshRejected reports whether err is OneLogin rejecting a refresh token,
+// e.g., because it expired or was revoked, rather than a transient failure.
+func refreshRejected(err error) bool {
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) {
+		return false
+	}
+
+	code := errResp.Response.StatusCode
+	return code == http.StatusBadRequest || code == http.StatusUnauthorized
+}
+
+// invalidateToken discards the token of the client if it is the one that
+// authorized req, so that a new token is issued for the next request. It
+// reports whether req was authorized by a token of the client.
+func (c *Client) invalidateToken(ctx context.Context, req *http.Request) bool {
+	accessToken, ok := bearer(req)
+	if c.tokenSource != nil || !ok {
+		return false
+	}
+
+	c.tokenMu.Lock()
+	if c.oauthToken == nil || accessToken != c.oauthToken.AccessToken {
+		// the token has already been replaced
+		c.tokenMu.Unlock()
+		return true
+	}
+	c.rejected = c.oauthToken.AccessToken
+	c.oauthToken = nil
+	c.tokenMu.Unlock()
+
+	if c.tokenStore != nil {
+		// best-effort, like saving tokens: acquireToken skips the rejected
+		// token if it can't be deleted
+		_ = c.tokenStore.Delete(ctx, c.clientID)
+	}
+
+	return true
+}
+
+// token returns the exported representation of t.
+func (t *oauthToken) token() *Token {
+	return &Token{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.refreshToken,
+		TokenType:    t.TokenType,
+		AccountID:    t.AccountID,
+		CreatedAt:    t.CreatedAt,
+		ExpiresIn:    t.ExpiresIn,
+	}
+}
+
+// newOauthToken returns an oauthToken of c from its exported representation.
+func newOauthToken(c *Client, t *Token) *oauthToken {
+	return &oauthToken{
+		AccessToken:  t.AccessToken,
+		AccountID:    t.AccountID,
+		CreatedAt:    t.CreatedAt,
+		ExpiresIn:    t.ExpiresIn,
+		TokenType:    t.TokenType,
+		refreshToken: t.RefreshToken,
+		client:       c,
+	}
+}
+
+// loadToken returns the token of the client saved in its TokenStore, or nil
+// if there is no usable token.
+func (c *Client) loadToken(ctx context.Context) (*oauthToken, error) {
+	if c.tokenStore == nil {
+		return nil, nil
+	}
+
+	t, err := c.tokenStore.Load(ctx, c.clientID)
+	if err != nil || t == nil {
+		return nil, err
+	}
+
+	token := newOauthToken(c, t)
+	if token.isExpired() {
+		return nil, c.tokenStore.Delete(ctx, c.clientID)
+	}
+
+	return token, nil
+}
+
+// saveToken saves the token in the TokenStore of the client, if any.
+func (c *Client) saveToken(ctx context.Context, t *oauthToken) error {
+	if c.tokenStore == nil {
+		return nil
+	}
+
+	return c.tokenStore.Save(ctx, c.clientID, t.token())
+}
+
+// MemoryTokenStore is a TokenStore that keeps tokens in memory, it can be
+// shared by the clients of a process.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]Token
+}
+
+// NewMemoryTokenStore returns an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]Token)}
+}
+
+// Load returns the token stored for key.
+func (s *MemoryTokenStore) Load(ctx context.Context, key string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[key]
+	if !ok {
+		return nil, nil
+	}
+
+	return &t, nil
+}
+
+// Save stores the token for key.
+func (s *MemoryTokenStore) Save(ctx context.Context, key string, t *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[key] = *t
+	return nil
+}
+
+// Delete removes the token stored for key.
+func (s *MemoryTokenStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, key)
+	return nil
+}
+
+// FileTokenStore is a TokenStore that keeps tokens in a file readable only by
+// its owner (0600), optionally encrypted with AES-GCM.
+type FileTokenStore struct {
+	mu   sync.Mutex
+	path string
+	aead cipher.AEAD // nil if the file is not encrypted
+}
+
+// NewFileTokenStore returns a FileTokenStore that keeps the tokens in the
+// file at path, in plaintext.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// NewEncryptedFileTokenStore returns a FileTokenStore that keeps the tokens
+// in the file at path, encrypted with key. The key must be 16, 24 or 32
+// bytes long to select AES-128, AES-192 or AES-256.
+func NewEncryptedFileTokenStore(path string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileTokenStore{path: path, aead: aead}, nil
+}
+
+// Load returns the token stored for key.
+func (s *FileTokenStore) Load(ctx context.Context, key string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens[key], nil
+}
+
+// Save stores the token for key.
+func (s *FileTokenStore) Save(ctx context.Context, key string, t *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[key] = t
+
+	return s.write(tokens)
+}
+
+// Delete removes the token stored for key.
+func (s *FileTokenStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[key]; !ok {
+		return nil
+	}
+	delete(tokens, key)
+
+	return s.write(tokens)
+}
+
+// read returns the tokens of the file, a missing file holds no tokens.
+func (s *FileTokenStore) read() (map[string]*Token, error) {
+	tokens := make(map[string]*Token)
+
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if s.aead != nil {
+		n := s.aead.NonceSize()
+		if len(data) < n {
+			return nil, errors.New("token file is corrupted")
+		}
+		data, err = s.aead.Open(nil, data[:n], data[n:], nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// write replaces the file with tokens. The file is written to a temporary
+// file first and then renamed, so that it is never partially written.
+func (s *FileTokenStore) write(tokens map[string]*Token) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		data = s.aead.Seal(nonce, nonce, data, nil)
+	}
+
+	// ioutil.TempFile creates the file with 0600 permissions
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
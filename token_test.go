@@ -0,0 +1,259 @@
+package onelogin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onelogin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	encrypted, err := NewEncryptedFileTokenStore(filepath.Join(dir, "encrypted.json"), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		store *FileTokenStore
+	}{
+		{"plaintext", NewFileTokenStore(filepath.Join(dir, "plaintext.json"))},
+		{"encrypted", encrypted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			want := &Token{AccessToken: "secret-token", CreatedAt: time.Unix(1234567890, 0).UTC(), ExpiresIn: 36000}
+
+			if got, err := tt.store.Load(ctx, "clientID"); got != nil || err != nil {
+				t.Fatalf("got: %v, %v, want: nil, nil", got, err)
+			}
+
+			if err := tt.store.Save(ctx, "clientID", want); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			fi, err := os.Stat(tt.store.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := fi.Mode().Perm(); perm != 0600 {
+				t.Errorf("permissions got: %v, want: %v", perm, os.FileMode(0600))
+			}
+			data, _ := ioutil.ReadFile(tt.store.path)
+			if tt.store.aead != nil && bytes.Contains(data, []byte(want.AccessToken)) {
+				t.Errorf("the token is stored in plaintext")
+			}
+
+			got, err := tt.store.Load(ctx, "clientID")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != *want {
+				t.Errorf("got: %+v, want: %+v", got, want)
+			}
+
+			if err := tt.store.Delete(ctx, "clientID"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got, err := tt.store.Load(ctx, "clientID"); got != nil || err != nil {
+				t.Errorf("got: %v, %v, want: nil, nil", got, err)
+			}
+		})
+	}
+}
+
+func TestAddAuthorization_tokenStore(t *testing.T) {
+	now = time.Now
+
+	tests := []struct {
+		name   string
+		stored *Token
+		want   string
+	}{
+		{"no stored token", nil, "token"},
+		{"valid stored token", &Token{AccessToken: "stored", CreatedAt: time.Now(), ExpiresIn: 3600}, "stored"},
+		{"expired stored token", &Token{AccessToken: "stored", CreatedAt: time.Now().Add(-time.Hour), ExpiresIn: 3600}, "token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mux, teardown := setup()
+			defer teardown()
+
+			store := NewMemoryTokenStore()
+			if tt.stored != nil {
+				store.Save(context.Background(), "clientID", tt.stored)
+			}
+			c.tokenStore = store
+
+			mux.HandleFunc("/api/1/test", func(w http.ResponseWriter, r *http.Request) {
+				if got, want := r.Header.Get("Authorization"), "bearer:"+tt.want; got != want {
+					t.Errorf("got: %v, want: %v", got, want)
+				}
+				fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+			})
+
+			req, _ := c.NewRequest("GET", "/api/1/test", nil)
+			if err := c.AddAuthorization(context.Background(), req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := c.Do(context.Background(), req, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			saved, _ := store.Load(context.Background(), "clientID")
+			if saved == nil || saved.AccessToken != tt.want {
+				t.Errorf("saved token got: %+v, want: %v", saved, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddAuthorization_concurrent(t *testing.T) {
+	now = time.Now
+
+	var issued int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&issued, 1)
+		<-release
+		fmt.Fprintf(w, `{"status":{"error":false,"code":200},"data":[{"access_token":"token","created_at":%q,"expires_in":36000}]}`,
+			time.Now().UTC().Format(time.RFC3339Nano))
+	}))
+	defer server.Close()
+
+	c, err := NewWithOptions(WithCredentials("clientID", "clientSecret"), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a caller whose context is canceled doesn't wait for the token
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := c.NewRequest("GET", "/api/1/test", nil)
+	if err := c.AddAuthorization(ctx, req); err != context.DeadlineExceeded {
+		t.Errorf("got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := c.NewRequest("GET", "/api/1/test", nil)
+			if err := c.AddAuthorization(context.Background(), req); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&issued); n != 1 {
+		t.Errorf("tokens issued got: %v, want: 1", n)
+	}
+}
+
+// failingTokenStore is a TokenStore that can't be written, e.g., a
+// FileTokenStore on a read-only filesystem.
+type failingTokenStore struct {
+	saves int32
+}
+
+func (s *failingTokenStore) Load(ctx context.Context, key string) (*Token, error) {
+	return nil, nil
+}
+
+func (s *failingTokenStore) Save(ctx context.Context, key string, t *Token) error {
+	atomic.AddInt32(&s.saves, 1)
+	return os.ErrPermission
+}
+
+func (s *failingTokenStore) Delete(ctx context.Context, key string) error {
+	return os.ErrPermission
+}
+
+func TestAddAuthorization_failingTokenStore(t *testing.T) {
+	now = time.Now
+
+	c, _, teardown := setup()
+	defer teardown()
+
+	store := &failingTokenStore{}
+	c.tokenStore = store
+
+	for i := 0; i < 3; i++ {
+		req, _ := c.NewRequest("GET", "/api/1/test", nil)
+		if err := c.AddAuthorization(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, want := req.Header.Get("Authorization"), "bearer:token"; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	}
+
+	// the token is kept in memory rather than issued again for each request
+	if n := atomic.LoadInt32(&store.saves); n != 1 {
+		t.Errorf("saves got: %v, want: 1", n)
+	}
+}
+
+// undeletableTokenStore is a MemoryTokenStore whose tokens can't be deleted.
+type undeletableTokenStore struct {
+	*MemoryTokenStore
+}
+
+func (s undeletableTokenStore) Delete(ctx context.Context, key string) error {
+	return os.ErrPermission
+}
+
+func TestClientDo_unauthorizedTokenStore(t *testing.T) {
+	now = time.Now
+
+	c, mux, teardown := setup()
+	defer teardown()
+
+	old := &Token{AccessToken: "old", CreatedAt: time.Now(), ExpiresIn: 3600}
+	store := undeletableTokenStore{NewMemoryTokenStore()}
+	store.Save(context.Background(), "clientID", old)
+	c.tokenStore = store
+	c.oauthToken = newOauthToken(c, old)
+
+	mux.HandleFunc("/api/1/test", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "bearer:old" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"status":{"error":true,"code":401,"type":"Unauthorized","message":"Authorization Information is incorrect"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+	})
+
+	req, _ := c.NewRequest("GET", "/api/1/test", nil)
+	if err := c.AddAuthorization(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the rejected token is still stored, it mustn't be reused
+	if _, err := c.Do(context.Background(), req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := req.Header.Get("Authorization"), "bearer:token"; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
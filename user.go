@@ -2,15 +2,26 @@ package onelogin
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/url"
+	"sync"
+	"time"
 )
 
 // UserService handles communications with the authentication related methods on OneLogin.
 type UserService struct {
 	*service
+
+	mu     sync.RWMutex
+	schema *CustomAttributeSchema // see LoadCustomAttributeSchema
 }
 
-// User represents a OneLogin user.
+// User represents a OneLogin user. Its timestamps are the strings returned by
+// OneLogin, they are parsed like a Timestamp by the methods named after them
+// (e.g., CreatedTime).
 type User struct {
 	ActivatedAt          string            `json:"activated_at"`
 	CreatedAt            string            `json:"created_at"`
@@ -29,7 +40,8 @@ type User struct {
 	LocaleCode           string            `json:"locale_code"`
 	PasswordChangedAt    string            `json:"password_changed_at"`
 	Phone                string            `json:"phone"`
-	Status               int64             `json:"status"`
+	Status               UserStatus        `json:"status"`
+	State                UserState         `json:"state"`
 	UpdatedAt            string            `json:"updated_at"`
 	DistinguishedName    string            `json:"distinguished_name"`
 	ExternalID           string            `json:"external_id"`
@@ -42,48 +54,142 @@ type User struct {
 	CustomAttributes     map[string]string `json:"custom_attributes"`
 }
 
-type getUserQuery struct {
-	AfterCursor string `url:"after_cursor,omitempty"`
+// ActivatedTime returns the time the user was activated, or the zero time if
+// it hasn't been. An error is returned if the timestamp can't be parsed.
+func (u *User) ActivatedTime() (time.Time, error) {
+	return parseTimestamp(u.ActivatedAt)
+}
+
+// CreatedTime returns the time the user was created.
+func (u *User) CreatedTime() (time.Time, error) {
+	return parseTimestamp(u.CreatedAt)
+}
+
+// InvitationSentTime returns the time the user was last sent an invite link,
+// or the zero time if none was sent.
+func (u *User) InvitationSentTime() (time.Time, error) {
+	return parseTimestamp(u.InvitationSentAt)
+}
+
+// LastLoginTime returns the time the user last logged in, or the zero time if
+// the user never logged in.
+func (u *User) LastLoginTime() (time.Time, error) {
+	return parseTimestamp(u.LastLogin)
+}
+
+// LockedUntilTime returns the time the user is locked until, or the zero time
+// if the user isn't locked.
+func (u *User) LockedUntilTime() (time.Time, error) {
+	return parseTimestamp(u.LockedUntil)
+}
+
+// PasswordChangedTime returns the time the password of the user was last
+// changed, or the zero time if it never was.
+func (u *User) PasswordChangedTime() (time.Time, error) {
+	return parseTimestamp(u.PasswordChangedAt)
+}
+
+// UpdatedTime returns the time the user was last updated.
+func (u *User) UpdatedTime() (time.Time, error) {
+	return parseTimestamp(u.UpdatedAt)
 }
 
 // GetUsers returns all the OneLogin users.
 func (s *UserService) GetUsers(ctx context.Context) ([]*User, error) {
-	u := "/api/1/users"
-
 	var users []*User
-	var afterCursor string
+	if err := s.Pages(nil).All(ctx, &users); err != nil {
+		return nil, err
+	}
 
-	for {
-		uu, err := addOptions(u, &getUserQuery{AfterCursor: afterCursor})
-		if err != nil {
-			return nil, err
-		}
+	return users, nil
+}
 
-		req, err := s.client.NewRequest("GET", uu, nil)
-		if err != nil {
-			return nil, err
-		}
+// Pages returns an Iterator over the pages of OneLogin users, its items are
+// *User.
+func (s *UserService) Pages(opt *PageOptions) *Iterator {
+	return newIterator(s.client, "/api/1/users", opt)
+}
 
-		if err := s.client.AddAuthorization(ctx, req); err != nil {
-			return nil, err
-		}
+// UserListOptions specifies the filters of ListUsers. String filters accept
+// a "*" wildcard (e.g., "Ali*").
+//
+// https://developers.onelogin.com/api-docs/1/users/get-users
+type UserListOptions struct {
+	Email             string `url:"email,omitempty"`
+	Username          string `url:"username,omitempty"`
+	FirstName         string `url:"firstname,omitempty"`
+	LastName          string `url:"lastname,omitempty"`
+	DirectoryID       int64  `url:"directory_id,omitempty"`
+	ExternalID        string `url:"external_id,omitempty"`
+	RoleID            int64  `url:"role_id,omitempty"`
+	GroupID           int64  `url:"group_id,omitempty"`
+	SamAccountName    string `url:"samaccountname,omitempty"`
+	UserPrincipalName string `url:"userprincipalname,omitempty"`
 
-		var us []*User
-		resp, err := s.client.Do(ctx, req, &us)
-		if err != nil {
-			return nil, err
-		}
-		users = append(users, us...)
-		if resp.PaginationAfterCursor == nil {
-			break
-		}
+	CreatedSince   time.Time `url:"created_since,omitempty"`
+	CreatedUntil   time.Time `url:"created_until,omitempty"`
+	UpdatedSince   time.Time `url:"updated_since,omitempty"`
+	UpdatedUntil   time.Time `url:"updated_until,omitempty"`
+	LastLoginSince time.Time `url:"last_login_since,omitempty"`
+	LastLoginUntil time.Time `url:"last_login_until,omitempty"`
+
+	// CustomAttributes filters on the values of custom attributes, by
+	// shortname.
+	CustomAttributes map[string]string `url:"-"`
 
-		afterCursor = *resp.PaginationAfterCursor
+	// Fields restricts the fields returned for each user (e.g., "id",
+	// "email"), all the fields are returned if empty.
+	Fields []string `url:"fields,comma,omitempty"`
+
+	// Sort orders users by a field, prefixed by "+" (ascending) or "-"
+	// (descending), e.g., "-created_at".
+	Sort string `url:"sort,omitempty"`
+
+	PageOptions `url:"-"`
+}
+
+// ListUsers returns the OneLogin users matching opt.
+func (s *UserService) ListUsers(ctx context.Context, opt *UserListOptions) ([]*User, error) {
+	var users []*User
+	if err := s.ListUsersPages(opt).All(ctx, &users); err != nil {
+		return nil, err
 	}
 
 	return users, nil
 }
 
+// ListUsersPages returns an Iterator over the pages of OneLogin users
+// matching opt, its items are *User.
+func (s *UserService) ListUsersPages(opt *UserListOptions) *Iterator {
+	it := newListIterator(s.client, "/api/1/users", opt)
+	if opt != nil && it.err == nil {
+		it.url, it.err = addCustomAttributes(it.url, opt.CustomAttributes)
+	}
+
+	return it
+}
+
+// addCustomAttributes adds the filters on custom attributes as URL query
+// parameters to s, "custom_attributes.<shortname>=<value>".
+func addCustomAttributes(s string, attributes map[string]string) (string, error) {
+	if len(attributes) == 0 {
+		return s, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return s, err
+	}
+
+	q := u.Query()
+	for name, value := range attributes {
+		q.Set("custom_attributes."+name, value)
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
 // GetUser returns a OneLogin user specified by id.
 func (s *UserService) GetUser(ctx context.Context, id int64) (*User, error) {
 	u := fmt.Sprintf("/api/1/users/%v", id)
@@ -104,6 +210,10 @@ func (s *UserService) GetUser(ctx context.Context, id int64) (*User, error) {
 		return nil, err
 	}
 
+	if len(users) != 1 {
+		return nil, errors.New("unexpected user response")
+	}
+
 	return users[0], nil
 }
 
@@ -135,10 +245,21 @@ func (s *UserService) GetCustomAttributes(ctx context.Context) ([]string, error)
 	return attrs, nil
 }
 
-// UpdateCustomAttributes returns a OneLogin user.
+// UpdateCustomAttributes sets custom attributes of a OneLogin user. If a
+// schema has been loaded with LoadCustomAttributeSchema, unknown attributes
+// are rejected before any request is sent.
 func (s *UserService) UpdateCustomAttributes(ctx context.Context, id int64, attributes map[string]string) error {
 	u := fmt.Sprintf("/api/1/users/%v/set_custom_attributes", id)
 
+	s.mu.RLock()
+	schema := s.schema
+	s.mu.RUnlock()
+	if schema != nil {
+		if err := schema.Validate(attributes); err != nil {
+			return err
+		}
+	}
+
 	post := map[string]interface{}{
 		"custom_attributes": attributes,
 	}
@@ -159,3 +280,462 @@ func (s *UserService) UpdateCustomAttributes(ctx context.Context, id int64, attr
 
 	return nil
 }
+
+// CreateUserRequest holds the fields of a user to create. FirstName and
+// LastName are required, as well as Email or Username.
+//
+// https://developers.onelogin.com/api-docs/1/users/create-user
+type CreateUserRequest struct {
+	Email             string `json:"email,omitempty"`
+	Username          string `json:"username,omitempty"`
+	FirstName         string `json:"firstname"`
+	LastName          string `json:"lastname"`
+	Company           string `json:"company,omitempty"`
+	Department        string `json:"department,omitempty"`
+	Title             string `json:"title,omitempty"`
+	Phone             string `json:"phone,omitempty"`
+	Notes             string `json:"notes,omitempty"`
+	DirectoryID       int64  `json:"directory_id,omitempty"`
+	GroupID           int64  `json:"group_id,omitempty"`
+	ExternalID        string `json:"external_id,omitempty"`
+	DistinguishedName string `json:"distinguished_name,omitempty"`
+	MemberOf          string `json:"member_of,omitempty"`
+	SamAccountName    string `json:"samaccountname,omitempty"`
+	UserPrincipalName string `json:"userprincipalname,omitempty"`
+	OpenidName        string `json:"openid_name,omitempty"`
+	LocaleCode        string `json:"locale_code,omitempty"`
+	ManagerAdID       int    `json:"manager_ad_id,omitempty"`
+}
+
+// Validate checks that the required fields are set.
+func (r *CreateUserRequest) Validate() error {
+	switch {
+	case r == nil:
+		return errors.New("no user to create")
+	case r.FirstName == "":
+		return errors.New("required field is missing: 'firstname'")
+	case r.LastName == "":
+		return errors.New("required field is missing: 'lastname'")
+	case r.Email == "" && r.Username == "":
+		return errors.New("required field is missing: 'email' or 'username'")
+	}
+
+	return nil
+}
+
+// CreateUser creates a OneLogin user and returns it.
+func (s *UserService) CreateUser(ctx context.Context, user *CreateUserRequest) (*User, error) {
+	u := "/api/1/users"
+
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.saveUser(ctx, "POST", u, user)
+}
+
+// UpdateUserRequest holds the fields of a user to update. Only the fields
+// that are set (i.e., not nil) are updated, see String and Int64 to set
+// them.
+//
+// https://developers.onelogin.com/api-docs/1/users/update-user
+type UpdateUserRequest struct {
+	Email             *string `json:"email,omitempty"`
+	Username          *string `json:"username,omitempty"`
+	FirstName         *string `json:"firstname,omitempty"`
+	LastName          *string `json:"lastname,omitempty"`
+	Company           *string `json:"company,omitempty"`
+	Department        *string `json:"department,omitempty"`
+	Title             *string `json:"title,omitempty"`
+	Phone             *string `json:"phone,omitempty"`
+	Notes             *string `json:"notes,omitempty"`
+	DirectoryID       *int64  `json:"directory_id,omitempty"`
+	GroupID           *int64  `json:"group_id,omitempty"`
+	ExternalID        *string `json:"external_id,omitempty"`
+	DistinguishedName *string `json:"distinguished_name,omitempty"`
+	MemberOf          *string `json:"member_of,omitempty"`
+	SamAccountName    *string `json:"samaccountname,omitempty"`
+	UserPrincipalName *string `json:"userprincipalname,omitempty"`
+	OpenidName        *string `json:"openid_name,omitempty"`
+	LocaleCode        *string `json:"locale_code,omitempty"`
+	ManagerAdID       *int64  `json:"manager_ad_id,omitempty"`
+}
+
+// Validate checks that at least one field is set, and that the required
+// fields are not emptied.
+func (r *UpdateUserRequest) Validate() error {
+	if r == nil || *r == (UpdateUserRequest{}) {
+		return errors.New("no field to update")
+	}
+
+	for name, v := range map[string]*string{"firstname": r.FirstName, "lastname": r.LastName} {
+		if v != nil && *v == "" {
+			return fmt.Errorf("required field can't be empty: '%s'", name)
+		}
+	}
+
+	return nil
+}
+
+// UpdateUser updates the fields of a OneLogin user that are set in user, and
+// returns the updated user.
+func (s *UserService) UpdateUser(ctx context.Context, id int64, user *UpdateUserRequest) (*User, error) {
+	u := fmt.Sprintf("/api/1/users/%v", id)
+
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.saveUser(ctx, "PUT", u, user)
+}
+
+// saveUser sends a request that creates or updates a user and returns the
+// user.
+func (s *UserService) saveUser(ctx context.Context, method, u string, body interface{}) (*User, error) {
+	req, err := s.client.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.AddAuthorization(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var users []*User
+	_, err = s.client.Do(ctx, req, &users)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) != 1 {
+		return nil, errors.New("unexpected user response")
+	}
+
+	return users[0], nil
+}
+
+// DeleteUser deletes a OneLogin user.
+//
+// https://developers.onelogin.com/api-docs/1/users/delete-user
+func (s *UserService) DeleteUser(ctx context.Context, id int64) error {
+	u := fmt.Sprintf("/api/1/users/%v", id)
+
+	return s.send(ctx, "DELETE", u, nil, nil)
+}
+
+type setPasswordParams struct {
+	Password             string `json:"password"`
+	PasswordConfirmation string `json:"password_confirmation"`
+	PasswordAlgorithm    string `json:"password_algorithm,omitempty"`
+	PasswordSalt         string `json:"password_salt,omitempty"`
+	ValidatePolicy       bool   `json:"validate_policy,omitempty"`
+}
+
+// SetPasswordClearText sets the password of a OneLogin user. If
+// validatePolicy is true, the password is checked against the password policy
+// of the user, an error wrapping ErrPasswordPolicy is returned if it
+// doesn't comply.
+//
+// https://developers.onelogin.com/api-docs/1/users/set-password-in-cleartext
+func (s *UserService) SetPasswordClearText(ctx context.Context, id int64, password string, validatePolicy bool) error {
+	u := fmt.Sprintf("/api/1/users/set_password_clear_text/%v", id)
+
+	p := setPasswordParams{
+		Password:             password,
+		PasswordConfirmation: password,
+		ValidatePolicy:       validatePolicy,
+	}
+
+	return s.send(ctx, "PUT", u, p, nil)
+}
+
+// SetPasswordUsingSalt sets the password of a OneLogin user from its salted
+// SHA-256 hash, see HashPasswordSHA256, so that the password itself is never
+// sent.
+//
+// https://developers.onelogin.com/api-docs/1/users/set-password-using-sha-256
+func (s *UserService) SetPasswordUsingSalt(ctx context.Context, id int64, passwordHash, salt string) error {
+	u := fmt.Sprintf("/api/1/users/set_password_using_salt/%v", id)
+
+	p := setPasswordParams{
+		Password:             passwordHash,
+		PasswordConfirmation: passwordHash,
+		PasswordAlgorithm:    "salt+sha256",
+		PasswordSalt:         salt,
+	}
+
+	return s.send(ctx, "PUT", u, p, nil)
+}
+
+// HashPasswordSHA256 returns the hex encoded SHA-256 hash of the password
+// prepended with the salt, as expected by SetPasswordUsingSalt.
+func HashPasswordSHA256(password, salt string) string {
+	sum := sha256.Sum256([]byte(salt + password))
+	return hex.EncodeToString(sum[:])
+}
+
+// UserStatus is the status of a OneLogin user.
+type UserStatus int64
+
+// The statuses of a OneLogin user.
+const (
+	UserStatusUnactivated               UserStatus = 0
+	UserStatusActive                    UserStatus = 1
+	UserStatusSuspended                 UserStatus = 2
+	UserStatusLocked                    UserStatus = 3
+	UserStatusPasswordExpired           UserStatus = 4
+	UserStatusAwaitingPasswordReset     UserStatus = 5
+	UserStatusPasswordPending           UserStatus = 7
+	UserStatusSecurityQuestionsRequired UserStatus = 8
+)
+
+func (s UserStatus) String() string {
+	switch s {
+	case UserStatusUnactivated:
+		return "unactivated"
+	case UserStatusActive:
+		return "active"
+	case UserStatusSuspended:
+		return "suspended"
+	case UserStatusLocked:
+		return "locked"
+	case UserStatusPasswordExpired:
+		return "password expired"
+	case UserStatusAwaitingPasswordReset:
+		return "awaiting password reset"
+	case UserStatusPasswordPending:
+		return "password pending"
+	case UserStatusSecurityQuestionsRequired:
+		return "security questions required"
+	}
+
+	return fmt.Sprintf("UserStatus(%d)", int64(s))
+}
+
+// UserState is the approval state of a OneLogin user.
+type UserState int64
+
+// The states of a OneLogin user.
+const (
+	UserStateUnapproved UserState = 0
+	UserStateApproved   UserState = 1
+	UserStateRejected   UserState = 2
+	UserStateUnlicensed UserState = 3
+)
+
+func (s UserState) String() string {
+	switch s {
+	case UserStateUnapproved:
+		return "unapproved"
+	case UserStateApproved:
+		return "approved"
+	case UserStateRejected:
+		return "rejected"
+	case UserStateUnlicensed:
+		return "unlicensed"
+	}
+
+	return fmt.Sprintf("UserState(%d)", int64(s))
+}
+
+// LockUser locks a OneLogin user for the given number of minutes. If minutes
+// is 0, the user is locked for the duration set in its user policy.
+//
+// https://developers.onelogin.com/api-docs/1/users/lock-user-account
+func (s *UserService) LockUser(ctx context.Context, id int64, minutes int) error {
+	u := fmt.Sprintf("/api/1/users/%v/lock_user", id)
+
+	p := map[string]interface{}{
+		"locked_until": minutes,
+	}
+
+	return s.send(ctx, "PUT", u, p, nil)
+}
+
+// UnlockUser unlocks a OneLogin user by setting its status back to active.
+func (s *UserService) UnlockUser(ctx context.Context, id int64) error {
+	return s.SetUserStatus(ctx, id, UserStatusActive)
+}
+
+// SetUserStatus sets the status of a OneLogin user.
+func (s *UserService) SetUserStatus(ctx context.Context, id int64, status UserStatus) error {
+	u := fmt.Sprintf("/api/1/users/%v", id)
+
+	p := map[string]interface{}{
+		"status": status,
+	}
+
+	return s.send(ctx, "PUT", u, p, nil)
+}
+
+// SetUserState sets the approval state of a OneLogin user.
+//
+// https://developers.onelogin.com/api-docs/1/users/set-user-state
+func (s *UserService) SetUserState(ctx context.Context, id int64, state UserState) error {
+	u := fmt.Sprintf("/api/1/users/%v/set_state", id)
+
+	p := map[string]interface{}{
+		"state": state,
+	}
+
+	return s.send(ctx, "PUT", u, p, nil)
+}
+
+// LogUserOut ends all the active sessions of a OneLogin user.
+//
+// https://developers.onelogin.com/api-docs/1/users/log-user-out
+func (s *UserService) LogUserOut(ctx context.Context, id int64) error {
+	u := fmt.Sprintf("/api/1/users/%v/logout", id)
+
+	return s.send(ctx, "PUT", u, nil, nil)
+}
+
+// SetUserGroup moves a OneLogin user into a group, the security policy of the
+// group then applies to the user.
+func (s *UserService) SetUserGroup(ctx context.Context, id, groupID int64) error {
+	_, err := s.UpdateUser(ctx, id, &UpdateUserRequest{GroupID: &groupID})
+	return err
+}
+
+// GetUserRoles returns the IDs of the roles assigned to a OneLogin user.
+//
+// https://developers.onelogin.com/api-docs/1/users/get-user-roles
+func (s *UserService) GetUserRoles(ctx context.Context, id int64) ([]int64, error) {
+	u := fmt.Sprintf("/api/1/users/%v/roles", id)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.AddAuthorization(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var v [][]int64
+	_, err = s.client.Do(ctx, req, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	var roleIDs []int64
+	if len(v) > 0 {
+		roleIDs = v[0]
+	}
+	return roleIDs, nil
+}
+
+type userRolesParams struct {
+	RoleIDs []int64 `json:"role_id_array"`
+}
+
+// AssignRoles assigns roles to a OneLogin user.
+//
+// https://developers.onelogin.com/api-docs/1/users/assign-role-to-user
+func (s *UserService) AssignRoles(ctx context.Context, id int64, roleIDs []int64) error {
+	u := fmt.Sprintf("/api/1/users/%v/add_roles", id)
+
+	return s.send(ctx, "PUT", u, userRolesParams{RoleIDs: roleIDs}, nil)
+}
+
+// RemoveRoles removes roles from a OneLogin user.
+//
+// https://developers.onelogin.com/api-docs/1/users/remove-role-from-user
+func (s *UserService) RemoveRoles(ctx context.Context, id int64, roleIDs []int64) error {
+	u := fmt.Sprintf("/api/1/users/%v/remove_roles", id)
+
+	return s.send(ctx, "PUT", u, userRolesParams{RoleIDs: roleIDs}, nil)
+}
+
+// SyncRoles makes roleIDs the exact set of roles assigned to a OneLogin
+// user, with the minimal number of changes. It returns the IDs of the roles
+// that were assigned and removed.
+func (s *UserService) SyncRoles(ctx context.Context, id int64, roleIDs []int64) (added, removed []int64, err error) {
+	current, err := s.GetUserRoles(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	added, removed = diffIDs(current, roleIDs)
+
+	if len(added) > 0 {
+		if err := s.AssignRoles(ctx, id, added); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := s.RemoveRoles(ctx, id, removed); err != nil {
+			return added, nil, err
+		}
+	}
+
+	return added, removed, nil
+}
+
+// diffIDs returns the IDs of desired missing from current, and the IDs of
+// current missing from desired, in their original order.
+func diffIDs(current, desired []int64) (added, removed []int64) {
+	in := func(ids []int64) map[int64]bool {
+		m := make(map[int64]bool, len(ids))
+		for _, id := range ids {
+			m[id] = true
+		}
+		return m
+	}
+	inCurrent, inDesired := in(current), in(desired)
+
+	for _, id := range desired {
+		if !inCurrent[id] {
+			added = append(added, id)
+			inCurrent[id] = true // skip duplicates
+		}
+	}
+	for _, id := range current {
+		if !inDesired[id] {
+			removed = append(removed, id)
+			inDesired[id] = true
+		}
+	}
+
+	return added, removed
+}
+
+// UserApp is an app a OneLogin user has access to.
+type UserApp struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Icon string `json:"icon"`
+	// Provisioned is the provisioning status of the user for the app.
+	Provisioned int64 `json:"provisioned"`
+	// Extension is true if the app is signed in through the browser extension.
+	Extension bool  `json:"extension"`
+	LoginID   int64 `json:"login_id"`
+	// Personal is true if the app was added by the user.
+	Personal bool `json:"personal"`
+}
+
+// GetUserApps returns the apps a OneLogin user has access to, such as the
+// apps a SAML assertion can be requested for.
+//
+// https://developers.onelogin.com/api-docs/1/users/get-apps-for-user
+func (s *UserService) GetUserApps(ctx context.Context, id int64) ([]*UserApp, error) {
+	u := fmt.Sprintf("/api/1/users/%v/apps", id)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.AddAuthorization(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var apps []*UserApp
+	_, err = s.client.Do(ctx, req, &apps)
+	if err != nil {
+		return nil, err
+	}
+
+	return apps, nil
+}
@@ -0,0 +1,299 @@
+package onelogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserService_ListUsers(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	want := url.Values{
+		"email":                      {"*@example.com"},
+		"role_id":                    {"42"},
+		"created_since":              {"2009-02-13T23:31:30Z"},
+		"custom_attributes.employee": {"yes"},
+		"fields":                     {"id,email"},
+		"sort":                       {"-created_at"},
+		"limit":                      {"10"},
+	}
+	mux.HandleFunc("/api/1/users", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query(); got.Encode() != want.Encode() {
+			t.Errorf("query got: %v, want: %v", got, want)
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"pagination":{"after_cursor":null},
+			"data":[{"id":1,"email":"alice@example.com"}]}`)
+	})
+
+	users, err := c.User.ListUsers(context.Background(), &UserListOptions{
+		Email:            "*@example.com",
+		RoleID:           42,
+		CreatedSince:     time.Unix(1234567890, 0).UTC(),
+		CustomAttributes: map[string]string{"employee": "yes"},
+		Fields:           []string{"id", "email"},
+		Sort:             "-created_at",
+		PageOptions:      PageOptions{Limit: 10},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 1 || users[0].Email != "alice@example.com" {
+		t.Errorf("got: %v, want: alice@example.com", users)
+	}
+}
+
+func TestUserService_CreateUser(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("method got: %v, want: POST", r.Method)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if got, want := string(b), `{"email":"alice@example.com","firstname":"Alice","lastname":"Smith"}`+"\n"; got != want {
+			t.Errorf("body got: %v, want: %v", got, want)
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[{"id":1,"email":"alice@example.com"}]}`)
+	})
+
+	_, err := c.User.CreateUser(context.Background(), &CreateUserRequest{Email: "alice@example.com", FirstName: "Alice"})
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	if _, err := c.User.CreateUser(context.Background(), nil); err == nil {
+		t.Error("expected error for a nil request, got nil")
+	}
+
+	user, err := c.User.CreateUser(context.Background(), &CreateUserRequest{Email: "alice@example.com", FirstName: "Alice", LastName: "Smith"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != 1 {
+		t.Errorf("got: %v, want: %v", user.ID, 1)
+	}
+}
+
+func TestUserService_DeleteUser(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	var deleted bool
+	mux.HandleFunc("/api/1/users/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("method got: %v, want: DELETE", r.Method)
+		}
+		deleted = true
+		fmt.Fprint(w, `{"status":{"error":false,"code":200,"type":"success","message":"Success"}}`)
+	})
+
+	if err := c.User.DeleteUser(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !deleted {
+		t.Error("the user wasn't deleted")
+	}
+}
+
+func TestUserService_UpdateUser(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" {
+			t.Errorf("method got: %v, want: PUT", r.Method)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if got, want := string(b), `{"department":"","group_id":2}`+"\n"; got != want {
+			t.Errorf("body got: %v, want: %v", got, want)
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[{"id":1,"group_id":2}]}`)
+	})
+
+	_, err := c.User.UpdateUser(context.Background(), 1, &UpdateUserRequest{})
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	if _, err := c.User.UpdateUser(context.Background(), 1, nil); err == nil {
+		t.Error("expected error for a nil request, got nil")
+	}
+
+	user, err := c.User.UpdateUser(context.Background(), 1, &UpdateUserRequest{Department: String(""), GroupID: Int64(2)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.GroupID != 2 {
+		t.Errorf("got: %v, want: %v", user.GroupID, 2)
+	}
+}
+
+func TestHashPasswordSHA256(t *testing.T) {
+	// echo -n 'saltpassword' | sha256sum
+	want := "13601bda4ea78e55a07b98866d2be6be0744e3866f13c00c811cab608a28f322"
+	if got := HashPasswordSHA256("password", "salt"); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestUserService_SetPasswordClearText(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/set_password_clear_text/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"status":{"error":true,"code":422,"type":"Unprocessable Entity","message":"Password does not meet the password policy requirements"}}`)
+	})
+
+	err := c.User.SetPasswordClearText(context.Background(), 1, "weak", true)
+	if !errors.Is(err, ErrPasswordPolicy) {
+		t.Errorf("got: %v, want: %v", err, ErrPasswordPolicy)
+	}
+}
+
+func TestUserStatus_String(t *testing.T) {
+	tests := []struct {
+		status fmt.Stringer
+		want   string
+	}{
+		{UserStatusActive, "active"},
+		{UserStatusSecurityQuestionsRequired, "security questions required"},
+		{UserStatus(6), "UserStatus(6)"},
+		{UserStateUnlicensed, "unlicensed"},
+		{UserState(9), "UserState(9)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.String(); got != tt.want {
+			t.Errorf("got: %v, want: %v", got, tt.want)
+		}
+	}
+}
+
+func TestUserService_state(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Client) error
+		path string
+		body string
+	}{
+		{"lock", func(c *Client) error { return c.User.LockUser(context.Background(), 1, 30) }, "/api/1/users/1/lock_user", `{"locked_until":30}`},
+		{"unlock", func(c *Client) error { return c.User.UnlockUser(context.Background(), 1) }, "/api/1/users/1", `{"status":1}`},
+		{"set state", func(c *Client) error { return c.User.SetUserState(context.Background(), 1, UserStateRejected) }, "/api/1/users/1/set_state", `{"state":2}`},
+		{"log out", func(c *Client) error { return c.User.LogUserOut(context.Background(), 1) }, "/api/1/users/1/logout", ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mux, teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc(tt.path, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "PUT" {
+					t.Errorf("method got: %v, want: PUT", r.Method)
+				}
+				b, _ := ioutil.ReadAll(r.Body)
+				if got := strings.TrimSpace(string(b)); got != tt.body {
+					t.Errorf("body got: %v, want: %v", got, tt.body)
+				}
+				fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+			})
+
+			if err := tt.call(c); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserService_SyncRoles(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/1/roles", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[[1,2,3]]}`)
+	})
+	bodies := make(map[string]string)
+	for _, path := range []string{"/api/1/users/1/add_roles", "/api/1/users/1/remove_roles"} {
+		path := path
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies[path] = strings.TrimSpace(string(b))
+			fmt.Fprint(w, `{"status":{"error":false,"code":200}}`)
+		})
+	}
+
+	added, removed, err := c.User.SyncRoles(context.Background(), 1, []int64{3, 4, 2, 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(added) != "[4]" || fmt.Sprint(removed) != "[1]" {
+		t.Errorf("got: %v %v, want: [4] [1]", added, removed)
+	}
+	if got := bodies["/api/1/users/1/add_roles"]; got != `{"role_id_array":[4]}` {
+		t.Errorf("add roles body got: %v", got)
+	}
+	if got := bodies["/api/1/users/1/remove_roles"]; got != `{"role_id_array":[1]}` {
+		t.Errorf("remove roles body got: %v", got)
+	}
+}
+
+func TestUserService_GetUserApps(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/1/apps", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[
+			{"id":838187,"icon":"https://example.com/icon.png","name":"AWS","provisioned":1,"extension":false,"login_id":42,"personal":false}]}`)
+	})
+
+	apps, err := c.User.GetUserApps(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := UserApp{ID: 838187, Name: "AWS", Icon: "https://example.com/icon.png", Provisioned: 1, LoginID: 42}
+	if len(apps) != 1 || *apps[0] != want {
+		t.Errorf("got: %+v, want: %+v", apps, want)
+	}
+}
+
+func TestUserService_SetUserGroup(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/1", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if got, want := strings.TrimSpace(string(b)), `{"group_id":7}`; got != want {
+			t.Errorf("body got: %v, want: %v", got, want)
+		}
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[{"id":1,"group_id":7}]}`)
+	})
+
+	if err := c.User.SetUserGroup(context.Background(), 1, 7); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUserService_GetUser_emptyResponse(t *testing.T) {
+	c, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/1/users/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"error":false,"code":200},"data":[]}`)
+	})
+
+	if _, err := c.User.GetUser(context.Background(), 1); err == nil {
+		t.Error("expected an error")
+	}
+}